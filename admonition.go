@@ -0,0 +1,64 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+const defaultAdmonitionLanguage = "en"
+
+var admonitionMarkerPattern = regexp.MustCompile(`^\[!([A-Za-z][\w-]*)\]\s*(.*)$`)
+
+// admonitionRegistry maps an admonition kind ("note", "tip", ...) to its titles by language
+var admonitionRegistry = map[string]map[string]string{
+	"note":      {"en": "Note", "pl": "Notatka"},
+	"tip":       {"en": "Tip", "pl": "Wskazówka"},
+	"important": {"en": "Important", "pl": "Ważne"},
+	"warning":   {"en": "Warning", "pl": "Uwaga"},
+	"caution":   {"en": "Caution", "pl": "Ostrożnie"},
+}
+
+// RegisterAdmonition adds a custom "> [!KIND]" label or replaces the titles of an existing one.
+// Titles are keyed by language code, e.g. {"en": "Danger", "pl": "Niebezpieczeństwo"}.
+func RegisterAdmonition(kind string, titles map[string]string) {
+	admonitionRegistry[strings.ToLower(kind)] = titles
+}
+
+func isRegisteredAdmonition(kind string) bool {
+	_, ok := admonitionRegistry[strings.ToLower(kind)]
+	return ok
+}
+
+// parseAdmonitionMarker reads the "[!KIND] optional title" opening line of a quote
+func parseAdmonitionMarker(ln string) (string, string, bool) {
+	matches := admonitionMarkerPattern.FindStringSubmatch(strings.TrimSpace(ln))
+	if matches == nil || !isRegisteredAdmonition(matches[1]) {
+		return "", "", false
+	}
+
+	return strings.ToLower(matches[1]), matches[2], true
+}
+
+// getAdmonitionTitle picks the title for the document language, falling back to English
+// and finally to the kind itself
+func getAdmonitionTitle(kind, language string) string {
+	titles := admonitionRegistry[kind]
+	if title, ok := titles[normalizeLanguageCode(language)]; ok {
+		return title
+	}
+	if title, ok := titles[defaultAdmonitionLanguage]; ok {
+		return title
+	}
+
+	return strings.ToUpper(kind[:1]) + kind[1:]
+}
+
+// normalizeLanguageCode turns "pl-PL" or "PL" into "pl"
+func normalizeLanguageCode(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if idx := strings.IndexAny(language, "-_"); idx >= 0 {
+		language = language[:idx]
+	}
+
+	return language
+}
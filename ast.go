@@ -0,0 +1,426 @@
+package main
+
+import (
+	"html"
+	"strings"
+)
+
+// Node is implemented by every element of the markdown document tree
+type Node interface {
+	node()
+}
+
+// Block is a structural element of a document (heading, paragraph, list, ...)
+type Block interface {
+	Node
+	block()
+}
+
+// Inline is a span of text inside a block (plain text, emphasis, link, ...)
+type Inline interface {
+	Node
+	inline()
+}
+
+// InlineContent keeps the raw markdown of a block together with its parsed inline nodes.
+// Inline parsing runs as a separate phase, after the whole block structure is known.
+type InlineContent struct {
+	Raw     string
+	Inlines []Inline
+}
+
+// ---------------------------------------------------------------------------
+// Block nodes
+// ---------------------------------------------------------------------------
+
+type Document struct {
+	Children  []Block
+	Footnotes []*FootnoteDefinition // referenced footnotes in the order of their numbers
+}
+
+// BlankLine represents a run of empty source lines between blocks
+type BlankLine struct{}
+
+type Heading struct {
+	Level      int
+	ID         string // unique slug used as the anchor target
+	Content    InlineContent
+	Attributes Attributes // a custom "#id" replaces the generated slug
+}
+
+type Paragraph struct {
+	Content    InlineContent
+	Attributes Attributes
+}
+
+type List struct {
+	Ordered bool
+	Items   []*ListItem
+}
+
+type ListItem struct {
+	Content  InlineContent
+	Task     bool // item opened with a "[ ]" or "[x]" checkbox
+	Checked  bool
+	Children []Block // nested lists and code blocks
+}
+
+type CodeBlock struct {
+	Language   string
+	Lines      []string
+	Attributes Attributes
+}
+
+type BlockQuote struct {
+	Callout  *InlineContent // leading "Label:" phrase, nil when the quote is not a callout
+	Children []Block
+}
+
+// Admonition is a "> [!NOTE]" style quote; an empty Title means the default title for the kind
+type Admonition struct {
+	Kind     string
+	Title    InlineContent
+	Children []Block
+}
+
+// Table is a GFM pipe table; Alignments holds "left", "center", "right" or "" per column
+type Table struct {
+	Alignments []string
+	Header     *TableRow
+	Rows       []*TableRow
+}
+
+type TableRow struct {
+	Cells []*TableCell
+}
+
+type TableCell struct {
+	Content InlineContent
+}
+
+// FootnoteDefinition is a "[^label]: text" block; it is rendered only in the footnotes section
+type FootnoteDefinition struct {
+	Label          string
+	Number         int // position in the footnotes section, 0 when never referenced
+	ReferenceCount int
+	Children       []Block
+}
+
+// LinkReferenceDefinition is a "[label]: url "title"" line; it is not rendered
+type LinkReferenceDefinition struct {
+	Label       string
+	Destination string
+	Title       string
+}
+
+// ThematicBreak is a "---", "***" or "___" line rendered as <hr>
+type ThematicBreak struct{}
+
+// TocMarker is the "[[toc]]" line replaced with the table of contents
+type TocMarker struct{}
+
+// HTMLBlock is raw HTML copied to the output unchanged
+type HTMLBlock struct {
+	Literal string
+}
+
+func (*Document) node()                {}
+func (*BlankLine) node()               {}
+func (*Heading) node()                 {}
+func (*Paragraph) node()               {}
+func (*List) node()                    {}
+func (*ListItem) node()                {}
+func (*CodeBlock) node()               {}
+func (*BlockQuote) node()              {}
+func (*Admonition) node()              {}
+func (*Table) node()                   {}
+func (*TableRow) node()                {}
+func (*TableCell) node()               {}
+func (*FootnoteDefinition) node()      {}
+func (*LinkReferenceDefinition) node() {}
+func (*ThematicBreak) node()           {}
+func (*TocMarker) node()               {}
+func (*HTMLBlock) node()               {}
+
+func (*Document) block()                {}
+func (*BlankLine) block()               {}
+func (*Heading) block()                 {}
+func (*Paragraph) block()               {}
+func (*List) block()                    {}
+func (*ListItem) block()                {}
+func (*CodeBlock) block()               {}
+func (*BlockQuote) block()              {}
+func (*Admonition) block()              {}
+func (*Table) block()                   {}
+func (*FootnoteDefinition) block()      {}
+func (*LinkReferenceDefinition) block() {}
+func (*ThematicBreak) block()           {}
+func (*TocMarker) block()               {}
+func (*HTMLBlock) block()               {}
+
+// ---------------------------------------------------------------------------
+// Inline nodes
+// ---------------------------------------------------------------------------
+
+type Text struct {
+	Value string
+}
+
+// SoftBreak is a line ending inside a paragraph
+type SoftBreak struct{}
+
+// HardBreak is a line ending marked with two trailing spaces or a backslash
+type HardBreak struct{}
+
+type Emphasis struct {
+	Children []Inline
+}
+
+type Strong struct {
+	Children []Inline
+}
+
+type Code struct {
+	Value string
+}
+
+// Strikethrough is "~~text~~"
+type Strikethrough struct {
+	Children []Inline
+}
+
+// Highlight is "==text=="
+type Highlight struct {
+	Children []Inline
+}
+
+// Subscript is "~text~", as in H~2~O
+type Subscript struct {
+	Children []Inline
+}
+
+// Superscript is "^text^", as in x^2^
+type Superscript struct {
+	Children []Inline
+}
+
+// Entity is a valid character reference such as "&nbsp;", written to the output as is
+type Entity struct {
+	Literal string
+}
+
+// RawHTML is an inline HTML tag or comment copied to the output unchanged
+type RawHTML struct {
+	Literal string
+}
+
+// Keyboard is a "[[Ctrl+C]]" key combination
+type Keyboard struct {
+	Value string
+}
+
+type Link struct {
+	Destination string
+	Title       string
+	Children    []Inline
+	Attributes  Attributes
+}
+
+type Image struct {
+	Source     string
+	Alt        string
+	Title      string
+	Attributes Attributes
+}
+
+// FootnoteReference is a "[^label]" mark; Definition stays nil when the label is not defined
+type FootnoteReference struct {
+	Label      string
+	Definition *FootnoteDefinition
+	Index      int // 1 for the first reference to the footnote, 2 for the second, ...
+}
+
+// FootnoteBackReference links a footnote back to one of its references
+type FootnoteBackReference struct {
+	Number int
+	Index  int
+}
+
+func (*Text) node()                  {}
+func (*SoftBreak) node()             {}
+func (*HardBreak) node()             {}
+func (*Emphasis) node()              {}
+func (*Strong) node()                {}
+func (*Code) node()                  {}
+func (*Strikethrough) node()         {}
+func (*Highlight) node()             {}
+func (*Subscript) node()             {}
+func (*Superscript) node()           {}
+func (*Keyboard) node()              {}
+func (*Entity) node()                {}
+func (*RawHTML) node()               {}
+func (*Link) node()                  {}
+func (*Image) node()                 {}
+func (*FootnoteReference) node()     {}
+func (*FootnoteBackReference) node() {}
+
+func (*Text) inline()                  {}
+func (*SoftBreak) inline()             {}
+func (*HardBreak) inline()             {}
+func (*Emphasis) inline()              {}
+func (*Strong) inline()                {}
+func (*Code) inline()                  {}
+func (*Strikethrough) inline()         {}
+func (*Highlight) inline()             {}
+func (*Subscript) inline()             {}
+func (*Superscript) inline()           {}
+func (*Keyboard) inline()              {}
+func (*Entity) inline()                {}
+func (*RawHTML) inline()               {}
+func (*Link) inline()                  {}
+func (*Image) inline()                 {}
+func (*FootnoteReference) inline()     {}
+func (*FootnoteBackReference) inline() {}
+
+// walkBlocks visits every block of the tree, parents before their children
+func walkBlocks(blocks []Block, visit func(Block)) {
+	for _, block := range blocks {
+		visit(block)
+		switch b := block.(type) {
+		case *List:
+			for _, item := range b.Items {
+				walkBlocks(item.Children, visit)
+			}
+		case *BlockQuote:
+			walkBlocks(b.Children, visit)
+		case *Admonition:
+			walkBlocks(b.Children, visit)
+		case *FootnoteDefinition:
+			walkBlocks(b.Children, visit)
+		}
+	}
+}
+
+// walkInlines visits every inline node, parents before their children
+func walkInlines(inlines []Inline, visit func(Inline)) {
+	for _, inline := range inlines {
+		visit(inline)
+		switch n := inline.(type) {
+		case *Emphasis:
+			walkInlines(n.Children, visit)
+		case *Strong:
+			walkInlines(n.Children, visit)
+		case *Link:
+			walkInlines(n.Children, visit)
+		case *Strikethrough:
+			walkInlines(n.Children, visit)
+		case *Highlight:
+			walkInlines(n.Children, visit)
+		case *Subscript:
+			walkInlines(n.Children, visit)
+		case *Superscript:
+			walkInlines(n.Children, visit)
+		}
+	}
+}
+
+// inlinePlainText returns the text of inline nodes without any markup
+func inlinePlainText(inlines []Inline) string {
+	var text strings.Builder
+	writePlainText(&text, inlines, true)
+	return text.String()
+}
+
+// inlineProseText is the plain text without code spans and image descriptions, the words a reader reads
+func inlineProseText(inlines []Inline) string {
+	var text strings.Builder
+	writePlainText(&text, inlines, false)
+	return text.String()
+}
+
+func writePlainText(text *strings.Builder, inlines []Inline, withCode bool) {
+	for _, inline := range inlines {
+		switch n := inline.(type) {
+		case *Text:
+			text.WriteString(n.Value)
+		case *Code:
+			if withCode {
+				text.WriteString(n.Value)
+			} else {
+				text.WriteString(" ")
+			}
+		case *Keyboard:
+			text.WriteString(n.Value)
+		case *Entity:
+			text.WriteString(html.UnescapeString(n.Literal))
+		case *SoftBreak, *HardBreak:
+			text.WriteString(" ")
+		case *Emphasis:
+			writePlainText(text, n.Children, withCode)
+		case *Strong:
+			writePlainText(text, n.Children, withCode)
+		case *Link:
+			writePlainText(text, n.Children, withCode)
+		case *Strikethrough:
+			writePlainText(text, n.Children, withCode)
+		case *Highlight:
+			writePlainText(text, n.Children, withCode)
+		case *Subscript:
+			writePlainText(text, n.Children, withCode)
+		case *Superscript:
+			writePlainText(text, n.Children, withCode)
+		case *Image:
+			if withCode {
+				text.WriteString(n.Alt)
+			}
+		}
+	}
+}
+
+// forEachInlineContent visits every block that carries inline markdown, depth first
+func forEachInlineContent(blocks []Block, visit func(*InlineContent)) {
+	visitInlineContent(blocks, visit, true)
+}
+
+// forEachRenderedInlineContent visits the inline markdown written by the renderer: the blocks
+// without footnote definitions, then the referenced footnotes in the order of their numbers
+func forEachRenderedInlineContent(doc *Document, visit func(*InlineContent)) {
+	visitInlineContent(doc.Children, visit, false)
+	for _, definition := range doc.Footnotes {
+		visitInlineContent(definition.Children, visit, false)
+	}
+}
+
+func visitInlineContent(blocks []Block, visit func(*InlineContent), withFootnotes bool) {
+	for _, block := range blocks {
+		switch b := block.(type) {
+		case *Heading:
+			visit(&b.Content)
+		case *Paragraph:
+			visit(&b.Content)
+		case *List:
+			for _, item := range b.Items {
+				visit(&item.Content)
+				visitInlineContent(item.Children, visit, withFootnotes)
+			}
+		case *BlockQuote:
+			if b.Callout != nil {
+				visit(b.Callout)
+			}
+			visitInlineContent(b.Children, visit, withFootnotes)
+		case *Admonition:
+			visit(&b.Title)
+			visitInlineContent(b.Children, visit, withFootnotes)
+		case *FootnoteDefinition:
+			if withFootnotes {
+				visitInlineContent(b.Children, visit, withFootnotes)
+			}
+		case *Table:
+			for _, row := range append([]*TableRow{b.Header}, b.Rows...) {
+				for _, cell := range row.Cells {
+					visit(&cell.Content)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var attributeTokenPattern = regexp.MustCompile(`^[ \t]*(#[^\s{}#.]+|\.[^\s{}#.]+|[A-Za-z_:][-\w:.]*=(?:"[^"]*"|'[^']*'|[^\s"'{}]+))`)
+
+// Attributes come from a "{#id .class key=value}" block written after an element
+type Attributes struct {
+	ID      string
+	Classes []string
+	Pairs   []AttributePair
+}
+
+type AttributePair struct {
+	Key   string
+	Value string
+}
+
+// parseAttributeBlock reads the attribute block starting at text[0] == '{'.
+// Returns false when the braces hold anything else than attributes.
+func parseAttributeBlock(text string) (Attributes, int, bool) {
+	attributes := Attributes{}
+	if !strings.HasPrefix(text, "{") {
+		return attributes, 0, false
+	}
+
+	pos := 1
+	for {
+		rest := text[pos:]
+		if closing := strings.TrimLeft(rest, " \t"); strings.HasPrefix(closing, "}") {
+			if pos == 1 {
+				return attributes, 0, false
+			}
+			return attributes, len(text) - len(closing) + 1, true
+		}
+
+		match := attributeTokenPattern.FindStringSubmatch(rest)
+		if match == nil {
+			return attributes, 0, false
+		}
+		attributes.add(match[1])
+		pos += len(match[0])
+	}
+}
+
+func (attributes Attributes) isEmpty() bool {
+	return attributes.ID == "" && len(attributes.Classes) == 0 && len(attributes.Pairs) == 0
+}
+
+func (attributes *Attributes) add(token string) {
+	switch token[0] {
+	case '#':
+		attributes.ID = token[1:]
+	case '.':
+		attributes.Classes = append(attributes.Classes, token[1:])
+	default:
+		key, value, _ := strings.Cut(token, "=")
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') {
+			value = value[1 : len(value)-1]
+		}
+
+		// "id=" and "class=" merge with "#id" and ".class" instead of repeating the attribute
+		switch strings.ToLower(key) {
+		case "id":
+			attributes.ID = value
+		case "class":
+			attributes.Classes = append(attributes.Classes, strings.Fields(value)...)
+		default:
+			attributes.Pairs = append(attributes.Pairs, AttributePair{Key: key, Value: value})
+		}
+	}
+}
+
+// withoutPairs drops the key=value pairs the element already renders from markdown syntax,
+// e.g. "src" of an image
+func (attributes Attributes) withoutPairs(names ...string) Attributes {
+	var pairs []AttributePair
+	for _, pair := range attributes.Pairs {
+		if !slices.Contains(names, strings.ToLower(pair.Key)) {
+			pairs = append(pairs, pair)
+		}
+	}
+
+	attributes.Pairs = pairs
+	return attributes
+}
+
+// cutTrailingAttributeBlock removes an attribute block ending the text. The block must be
+// separated from the preceding text by whitespace.
+func cutTrailingAttributeBlock(text string) (string, Attributes, bool) {
+	trimmed := strings.TrimRight(text, " \t")
+	start := strings.LastIndexByte(trimmed, '{')
+	if start <= 0 || !strings.HasSuffix(trimmed, "}") || !strings.ContainsAny(trimmed[start-1:start], " \t\n") {
+		return text, Attributes{}, false
+	}
+
+	attributes, length, ok := parseAttributeBlock(trimmed[start:])
+	if !ok || start+length != len(trimmed) {
+		return text, Attributes{}, false
+	}
+
+	rest := strings.TrimRight(trimmed[:start], " \t\n")
+	if rest == "" {
+		return text, Attributes{}, false
+	}
+
+	return rest, attributes, true
+}
+
+// buildAttributes renders the attributes; baseClass goes before the classes from the block
+func buildAttributes(attributes Attributes, baseClass string) string {
+	var html strings.Builder
+	if attributes.ID != "" {
+		fmt.Fprintf(&html, " id=\"%s\"", escapeHTML(attributes.ID))
+	}
+
+	classes := attributes.Classes
+	if baseClass != "" {
+		classes = append([]string{baseClass}, classes...)
+	}
+	if len(classes) > 0 {
+		fmt.Fprintf(&html, " class=\"%s\"", escapeHTML(strings.Join(classes, " ")))
+	}
+
+	for _, pair := range attributes.Pairs {
+		fmt.Fprintf(&html, " %s=\"%s\"", pair.Key, escapeHTML(pair.Value))
+	}
+
+	return html.String()
+}
@@ -3,226 +3,213 @@ package main
 import (
 	"bytes"
 	"fmt"
-	"regexp"
-	"strings"
-	"text/template"
+	"html/template"
+	"io"
+	texttemplate "text/template"
+	"time"
 )
 
 const defaultDocumentTitle = "Converted Document"
-const yamlFrontMatterDelimiter = "---"
 
-var markdownImagePattern = regexp.MustCompile(`^!\[([^\]]*)\]\(([^)]+)\)$`)
-var rawHTMLImagePattern = regexp.MustCompile(`(?i)^<img\b[^>]*>$`)
+// ConvertOptions switches optional conversion features; the zero value gives strict
+// CommonMark output without any extension
+type ConvertOptions struct {
+	HeadingAnchors bool               // add a self-link <a class="anchor"> to every heading
+	Strikethrough  bool               // ~~text~~ → <del>
+	Highlight      bool               // ==text== → <mark>
+	Subscript      bool               // H~2~O → <sub>
+	Superscript    bool               // x^2^ → <sup>
+	Keyboard       bool               // [[Ctrl+C]] → <kbd>
+	SafeHTML       bool               // sanitize raw HTML, attribute blocks and URLs instead of passing them through
+	HTMLPolicy     *SanitizerPolicy   // allowlist used in safe mode, nil for DefaultSanitizerPolicy
+	TextTemplate   bool               // execute the template with text/template, without contextual escaping
+	Schema         *FrontMatterSchema // required keys and value types of the front matter, nil to accept anything
+}
+
+// ConversionResult is the converted document together with the sanitizer diagnostics
+type ConversionResult struct {
+	HTML        string
+	Diagnostics []Diagnostic // tags and attributes removed in safe mode
+}
+
+// DefaultConvertOptions enables all inline extensions
+func DefaultConvertOptions() ConvertOptions {
+	return ConvertOptions{
+		Strikethrough: true,
+		Highlight:     true,
+		Subscript:     true,
+		Superscript:   true,
+		Keyboard:      true,
+	}
+}
 
 // ConvertMarkdownToHTML converts markdown to HTML using a template file
 func ConvertMarkdownToHTML(markdown string, templateText string, title string) (string, error) {
-	bodyMarkdown, data := parseLeadingYamlFrontMatter(markdown)
+	return ConvertMarkdownToHTMLWithOptions(markdown, templateText, title, DefaultConvertOptions())
+}
+
+// ConvertMarkdownToHTMLWithOptions converts markdown to HTML using a template file and conversion options
+func ConvertMarkdownToHTMLWithOptions(markdown string, templateText string, title string, options ConvertOptions) (string, error) {
+	result, err := ConvertMarkdownDocument(markdown, templateText, title, options)
+	return result.HTML, err
+}
+
+// ConvertMarkdownDocument converts markdown to HTML using a template file and reports
+// the elements removed by the sanitizer in safe mode
+func ConvertMarkdownDocument(markdown string, templateText string, title string, options ConvertOptions) (ConversionResult, error) {
+	return convertDocument(markdown, title, options, func(data *TemplateData, diagnostics *[]Diagnostic) (templateExecutor, error) {
+		return parseDocumentTemplate(templateText, options, diagnostics)
+	})
+}
+
+// ConvertMarkdownWithLayouts converts markdown using the layouts and partials of a template
+// directory; the "layout" front matter key selects the layout
+func ConvertMarkdownWithLayouts(markdown string, templatesDir string, title string, options ConvertOptions) (ConversionResult, error) {
+	return convertDocument(markdown, title, options, func(data *TemplateData, diagnostics *[]Diagnostic) (templateExecutor, error) {
+		return parseLayoutTemplate(templatesDir, data.Layout, options, diagnostics)
+	})
+}
+
+// templateLoader parses the template of the document once its front matter is known
+type templateLoader func(data *TemplateData, diagnostics *[]Diagnostic) (templateExecutor, error)
+
+func convertDocument(markdown string, title string, options ConvertOptions, loadTemplate templateLoader) (ConversionResult, error) {
+	bodyMarkdown, data, err := parseLeadingFrontMatter(markdown)
+	if err != nil {
+		return ConversionResult{}, fmt.Errorf("error parsing front matter: %w", err)
+	}
+	if options.Schema != nil {
+		if err := options.Schema.validate(data.Meta); err != nil {
+			return ConversionResult{}, fmt.Errorf("front matter does not match the schema:\n%w", err)
+		}
+	}
 
 	// Parse template
-	template, err := template.New("document").Parse(templateText)
+	// markdownify in the template reports to the same diagnostics as the content
+	var templateDiagnostics []Diagnostic
+	template, err := loadTemplate(&data, &templateDiagnostics)
 	if err != nil {
-		return "", fmt.Errorf("error parsing template: %w", err)
+		return ConversionResult{}, fmt.Errorf("error parsing template: %w", err)
 	}
 
 	// Convert markdown to HTML content (without the full HTML structure)
-	htmlContent := generateHtmlBodyFromMarkdown(bodyMarkdown)
+	diagnostics := generateHtmlBodyFromMarkdown(bodyMarkdown, &data, options)
 
 	resolveTemplateTitle(&data, title)
 
 	// Execute template
 	var buf bytes.Buffer
-	data.Content = htmlContent
 	err = template.Execute(&buf, data)
 	if err != nil {
-		return "", fmt.Errorf("error executing template: %w", err)
+		return ConversionResult{}, fmt.Errorf("error executing template: %w", err)
 	}
 
-	return buf.String(), nil
+	return ConversionResult{HTML: buf.String(), Diagnostics: append(diagnostics, templateDiagnostics...)}, nil
 }
 
+// templateExecutor is implemented by both html/template and text/template
+type templateExecutor interface {
+	Execute(out io.Writer, data any) error
+}
+
+// parseDocumentTemplate uses html/template, which escapes metadata according to its context;
+// text/template is kept for templates that depend on raw output
+func parseDocumentTemplate(templateText string, options ConvertOptions, diagnostics *[]Diagnostic) (templateExecutor, error) {
+	if options.TextTemplate {
+		textTemplate, err := texttemplate.New("document").Funcs(templateFuncs(options, diagnostics)).Parse(templateText)
+		if err != nil {
+			return nil, err
+		}
+		return textTemplate, nil
+	}
+
+	htmlTemplate, err := template.New("document").Funcs(templateFuncs(options, diagnostics)).Parse(templateText)
+	if err != nil {
+		return nil, err
+	}
+	return htmlTemplate, nil
+}
+
+// TemplateData is passed to the template; the generated HTML fields are trusted and not escaped
 type TemplateData struct {
 	Title             string
 	Description       string
 	Date              string
+	ParsedDate        time.Time // Date as time, zero when it is missing or not valid
 	Author            string
 	Language          string
 	CoverImage        string
 	CoverImageCaption string
 	PageFooter        string
-	Content           string
-}
-
-// Level information for the two-pass list processing
-type ListLevel struct {
-	Depth    int    // Number of spaces in source
-	ListType string // "ul" or "ol"
-}
-
-func isCodeFenceLine(ln string) bool {
-	trimmed := strings.TrimSpace(ln)
-	return strings.HasPrefix(trimmed, "```")
-}
-
-func getCodeFenceLanguage(ln string) string {
-	trimmed := strings.TrimSpace(ln)
-	if !strings.HasPrefix(trimmed, "```") {
-		return ""
-	}
-
-	return strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
-}
-
-func isListLine(ln string) bool {
-	if strings.HasPrefix(ln, "- ") {
-		return true
-	}
-	matched, _ := regexp.MatchString(`^\d+\.\s`, ln)
-	return matched
-}
-
-func isBlockQuoteLine(ln string) bool {
-	return strings.HasPrefix(strings.TrimSpace(ln), ">")
-}
-
-func isInsideListBlock(ln string, insideCodeBlock *bool) bool {
-	trimmed := strings.TrimSpace(ln)
-	if isCodeFenceLine(trimmed) {
-		*insideCodeBlock = !*insideCodeBlock
-		return true
-	}
-	if *insideCodeBlock {
-		return true
-	}
-	if isListLine(trimmed) || trimmed == "" {
-		return true
-	}
-	return false
-}
-
-func createIndentation(steps int) string {
-	return strings.Repeat("    ", steps)
+	Layout            string // layout of the template directory, "default" when empty
+	TocLevels         string // heading levels listed in the table of contents, e.g. "2-3"
+	Content           template.HTML
+	TOC               template.HTML     // table of contents as a nested list of links
+	Footnotes         template.HTML     // footnotes section, also appended to Content
+	Headings          []DocumentHeading // headings listed in the table of contents
+	TasksDone         int               // checked task list items
+	TasksTotal        int               // all task list items
+	WordCount         int               // words of the text; code and image descriptions are not counted
+	ReadingMinutes    int               // reading time at the reading speed of the document language
+	Excerpt           string            // plain text of the first paragraph
+	FirstImage        string            // source of the first image, e.g. for a social media card
+	HeadingCount      int
+	CodeBlockCount    int
+	ImageCount        int
+	Meta              map[string]any // every front matter key, with lists and nested maps
+}
+
+// DocumentHeading describes a heading for the table of contents
+type DocumentHeading struct {
+	Level int
+	Text  string
+	ID    string
 }
 
 // converts markdown to HTML content (main converter function)
 func GenerateHtmlBody(markdown string) string {
-	bodyMarkdown, _ := parseLeadingYamlFrontMatter(markdown)
-	return generateHtmlBodyFromMarkdown(bodyMarkdown)
-}
-
-func generateHtmlBodyFromMarkdown(markdown string) string {
-	var result strings.Builder
-
-	lines := strings.Split(markdown, "\n")
-
-	lineIdx := 0
-	for lineIdx < len(lines) {
-		currentLine := lines[lineIdx]
-
-		// Handle multiline code blocks (```)
-		if isCodeFenceLine(currentLine) {
-			newIdx, codeBlock := processCodeBlock(lineIdx, lines, "")
-			result.WriteString(codeBlock)
-			lineIdx = newIdx
-			continue
-		}
-
-		// Check if this line starts a list block
-		if isListLine(strings.TrimSpace(currentLine)) {
-			listBlock := []string{}
-			isInsideCode := false
-			for lineIdx < len(lines) && isInsideListBlock(lines[lineIdx], &isInsideCode) {
-				listBlock = append(listBlock, lines[lineIdx])
-				lineIdx++
-			}
-			listHTML := processListBlock(listBlock)
-			result.WriteString(listHTML)
-			continue
+	bodyMarkdown, data, _ := parseLeadingFrontMatter(markdown)
+	generateHtmlBodyFromMarkdown(bodyMarkdown, &data, DefaultConvertOptions())
+	return string(data.Content)
+}
+
+// generateHtmlBodyFromMarkdown fills the generated fields of data: Content, TOC, Headings and statistics.
+// Returns the diagnostics of the sanitizer in safe mode.
+func generateHtmlBodyFromMarkdown(markdown string, data *TemplateData, options ConvertOptions) []Diagnostic {
+	sanitizer := newHTMLSanitizer(options)
+	doc := parseMarkdown(markdown, options, sanitizer)
+
+	minLevel, maxLevel := parseTocLevels(data.TocLevels)
+	data.Headings = collectDocumentHeadings(doc, minLevel, maxLevel)
+	data.TOC = template.HTML(renderTableOfContents(data.Headings))
+	data.TasksDone, data.TasksTotal = countTaskListItems(doc)
+	collectDocumentStatistics(doc, data)
+	footnotes := renderFootnotes(doc, data, options, sanitizer)
+	data.Footnotes = template.HTML(footnotes)
+	data.Content = template.HTML(renderHTML(doc, data, options, sanitizer) + footnotes)
+	return sanitizer.reportedDiagnostics()
+}
+
+// countTaskListItems returns the number of checked and of all task list items
+func countTaskListItems(doc *Document) (int, int) {
+	done, total := 0, 0
+	walkBlocks(doc.Children, func(block Block) {
+		list, ok := block.(*List)
+		if !ok {
+			return
 		}
 
-		// Skip empty lines. Add single empty HTML line only
-		if currentLine == "" {
-			lineIdx++
-			// Skip consecutive empty lines
-			for lineIdx < len(lines) && strings.TrimSpace(lines[lineIdx]) == "" {
-				lineIdx++
+		for _, item := range list.Items {
+			if item.Task {
+				total++
+				if item.Checked {
+					done++
+				}
 			}
-			result.WriteString("\n")
-			continue
 		}
+	})
 
-		// Process single non-list line
-		processedLine := processSingleLine(currentLine)
-		if processedLine != "" {
-			result.WriteString(processedLine)
-			result.WriteString("\n")
-		}
-
-		lineIdx++
-	}
-
-	return result.String()
-}
-
-func parseLeadingYamlFrontMatter(markdown string) (string, TemplateData) {
-	lines := strings.Split(markdown, "\n")
-	if len(lines) < 2 || strings.TrimSpace(lines[0]) != yamlFrontMatterDelimiter {
-		return markdown, TemplateData{}
-	}
-
-	closingIdx := findYamlFrontmatterClosingLine(lines)
-	if closingIdx < 0 {
-		return markdown, TemplateData{}
-	}
-
-	metadata := extractTemplateDataFromFrontMatter(lines[1:closingIdx])
-	return strings.Join(lines[closingIdx+1:], "\n"), metadata
-}
-
-func findYamlFrontmatterClosingLine(lines []string) int {
-	for lineIdx := 1; lineIdx < len(lines); lineIdx++ {
-		if strings.TrimSpace(lines[lineIdx]) == yamlFrontMatterDelimiter {
-			return lineIdx
-		}
-	}
-
-	return -1
-}
-
-func extractTemplateDataFromFrontMatter(lines []string) TemplateData {
-	data := TemplateData{}
-
-	for _, line := range lines {
-		key, value, ok := parseFrontMatterLine(line)
-		if !ok {
-			continue
-		}
-
-		setTemplateDataField(&data, key, value)
-	}
-
-	return data
-}
-
-func parseFrontMatterLine(line string) (string, string, bool) {
-	trimmed := strings.TrimSpace(line)
-	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
-		return "", "", false
-	}
-
-	key, value, ok := strings.Cut(trimmed, ":")
-	if !ok {
-		return "", "", false
-	}
-
-	key = strings.TrimSpace(key)
-	value = strings.TrimSpace(value)
-	value = strings.Trim(value, `"'`)
-
-	if key == "" {
-		return "", "", false
-	}
-
-	return key, value, true
+	return done, total
 }
 
 func resolveTemplateTitle(data *TemplateData, title string) {
@@ -235,418 +222,3 @@ func resolveTemplateTitle(data *TemplateData, title string) {
 		data.Title = defaultDocumentTitle
 	}
 }
-
-func setTemplateDataField(data *TemplateData, key, value string) {
-	switch key {
-	case "title":
-		data.Title = value
-	case "description":
-		data.Description = value
-	case "date":
-		data.Date = value
-	case "author":
-		data.Author = value
-	case "language":
-		data.Language = value
-	case "coverImage":
-		data.CoverImage = value
-	case "coverImageCaption":
-		data.CoverImageCaption = value
-	case "pageFooter":
-		data.PageFooter = value
-	}
-}
-
-func processCodeBlock(lineIdx int, lines []string, indentation string) (int, string) {
-	var codeBlock strings.Builder
-	ln := lines[lineIdx]
-	depth := getLineDepth(ln)
-	language := getCodeFenceLanguage(ln)
-
-	lineIdx++
-	startIdx := lineIdx
-	for lineIdx < len(lines) && !isCodeFenceLine(lines[lineIdx]) {
-		lineIdx++
-	}
-	if startIdx < lineIdx {
-		extraAttributes := buildDataLanguageAttribute(language)
-		codeBlock.WriteString(indentation + "<div class=\"code\"" + extraAttributes + ">\n")
-		codeBlock.WriteString(indentation + "<pre><code>")
-		for idx := startIdx; idx < lineIdx; idx++ {
-			codeBlock.WriteString(escapeHTML(trimCodeLineIndentation(lines[idx], depth)))
-			if idx < lineIdx-1 {
-				codeBlock.WriteString("\n")
-			}
-		}
-		codeBlock.WriteString("</code></pre>\n" + indentation + "</div>\n")
-	}
-
-	lineIdx++ // Skip closing ```
-	return lineIdx, codeBlock.String()
-}
-
-func buildDataLanguageAttribute(language string) string {
-	if language != "" {
-		return fmt.Sprintf(" data-language=\"%s\"", escapeHTML(language))
-	}
-
-	return ""
-}
-
-func getLineDepth(ln string) int {
-	depth := 0
-	for _, char := range ln {
-		switch char {
-		case ' ':
-			depth++
-		case '\t':
-			depth += 4
-		default:
-			return depth
-		}
-	}
-	return depth
-}
-
-func trimCodeLineIndentation(line string, depth int) string {
-	if len(line) <= depth {
-		return ""
-	}
-
-	return line[depth:]
-}
-
-// Process a block of list lines using the two-pass algorithm
-func processListBlock(lines []string) string {
-	if len(lines) == 0 {
-		return ""
-	}
-
-	var result strings.Builder
-	openTags := []string{} // Track open list tags for proper closing
-
-	lineIdx := 0
-	level := 0
-	for lineIdx < len(lines) {
-		currentLine := lines[lineIdx]
-		trimmed := strings.TrimSpace(currentLine)
-		currentDepth := getLineDepth(currentLine)
-
-		if trimmed == "" {
-			lineIdx++
-			continue
-		}
-
-		if lineIdx == 0 {
-			listType := "ol"
-			if strings.HasPrefix(trimmed, "- ") {
-				listType = "ul"
-			}
-
-			// Calculate indentation for block elements (ul/ol): 0, 8, 16, ...
-			blockIndent := createIndentation(2 * level)
-			tag := fmt.Sprintf("<%s>", listType)
-			result.WriteString(blockIndent + tag + "\n")
-			openTags = append(openTags, listType)
-		}
-
-		// Extract and process list item content
-		var content string
-		if after, ok := strings.CutPrefix(trimmed, "- "); ok {
-			content = after
-		} else {
-			// Ordered list - remove number and dot
-			re := regexp.MustCompile(`^\d+\.\s(.*)`)
-			matches := re.FindStringSubmatch(trimmed)
-			if len(matches) > 1 {
-				content = matches[1]
-			}
-		}
-
-		// Generate <li> with proper indentation: 4, 12, 20, ...
-		lineIndent := createIndentation(2*level + 1)
-		result.WriteString(fmt.Sprintf("%s<li>%s", lineIndent, processInlineElements(content)))
-
-		// Move to next line and skip empty lines
-		lineIdx++
-		for lineIdx < len(lines) && strings.TrimSpace(lines[lineIdx]) == "" {
-			lineIdx++
-		}
-
-		// Look ahead to see if next item is deeper (for nested lists)
-		nextDepth := -1
-		nextIsCode := false
-		if lineIdx < len(lines) {
-			// skip empty lines
-			line := lines[lineIdx]
-			isNextListLine := isListLine(strings.TrimSpace(line))
-			nextIsCode = isCodeFenceLine(line)
-			nextDepth = getLineDepth(line)
-			if nextIsCode || !isNextListLine {
-				nextDepth = currentDepth
-			}
-		}
-
-		if nextIsCode {
-			blockIndent := createIndentation(2 + 2*level)
-			newIdx, codeBlock := processCodeBlock(lineIdx, lines, blockIndent)
-			result.WriteString("\n" + codeBlock + lineIndent)
-			lineIdx = newIdx
-		}
-
-		isNextLineIsDeeperList := nextDepth >= 0 && nextDepth > currentDepth
-		isNextLineIsShallowerList := nextDepth >= 0 && level >= 0 && nextDepth < currentDepth
-		if isNextLineIsDeeperList {
-			level++
-			listType := "ol"
-			if strings.HasPrefix(strings.TrimSpace(lines[lineIdx]), "- ") {
-				listType = "ul"
-			}
-
-			// Calculate indentation for block elements (ul/ol): 0, 8, 16, ...
-			blockIndent := createIndentation(2 * level)
-			tag := fmt.Sprintf("<%s>", listType)
-			result.WriteString("\n" + blockIndent + tag + "\n")
-			openTags = append(openTags, listType)
-		} else if isNextLineIsShallowerList {
-			result.WriteString("</li>\n")
-
-			// Close nested list
-			if len(openTags) > 0 {
-				listType := openTags[len(openTags)-1]
-				blockIndent := createIndentation(2 * level)
-				result.WriteString(blockIndent + fmt.Sprintf("</%s>", listType) + "\n")
-				openTags = openTags[:len(openTags)-1]
-			}
-			// Close the parent <li>
-			level--
-			blockIndent := createIndentation(2*level + 1)
-			result.WriteString(blockIndent + "</li>\n")
-		} else if level >= 0 {
-			// Same level - close previous <li>
-			result.WriteString("</li>\n")
-		}
-
-	}
-
-	// Close all remaining open lists and list items
-	for i := len(openTags) - 1; i >= 0; i-- {
-		listType := openTags[i]
-		blockIndent := createIndentation(2 * i)
-		result.WriteString(blockIndent + fmt.Sprintf("</%s>", listType) + "\n")
-
-		// If this list is nested (not the outermost), close the <li> that contains it
-		if i > 0 {
-			blockIndent := createIndentation(2*i - 1)
-			result.WriteString(blockIndent + "</li>\n")
-		}
-	}
-
-	return result.String()
-}
-
-func processSingleLine(line string) string {
-	trimmed := strings.TrimSpace(line)
-
-	// Empty lines
-	if trimmed == "" {
-		return ""
-	}
-
-	if rawHTMLImagePattern.MatchString(trimmed) {
-		return trimmed
-	}
-
-	if html, ok := renderMarkdownImage(trimmed); ok {
-		return html
-	}
-
-	// Block quotes
-	if isBlockQuoteLine(trimmed) {
-		return processBlockQuote(trimmed)
-	}
-
-	// Headers
-	if strings.HasPrefix(trimmed, "#### ") {
-		content := strings.TrimPrefix(trimmed, "#### ")
-		return fmt.Sprintf("<h4>%s</h4>", processInlineElements(content))
-	}
-	if strings.HasPrefix(trimmed, "### ") {
-		content := strings.TrimPrefix(trimmed, "### ")
-		return fmt.Sprintf("<h3>%s</h3>", processInlineElements(content))
-	}
-	if strings.HasPrefix(trimmed, "## ") {
-		content := strings.TrimPrefix(trimmed, "## ")
-		return fmt.Sprintf("<h2>%s</h2>", processInlineElements(content))
-	}
-	if strings.HasPrefix(trimmed, "# ") {
-		content := strings.TrimPrefix(trimmed, "# ")
-		return fmt.Sprintf("<h1>%s</h1>", processInlineElements(content))
-	}
-
-	// Regular paragraphs
-	return fmt.Sprintf("<p>%s</p>", processInlineElements(trimmed))
-}
-
-func renderMarkdownImage(line string) (string, bool) {
-	matches := markdownImagePattern.FindStringSubmatch(line)
-	if len(matches) != 3 {
-		return "", false
-	}
-
-	alt := matches[1]
-	src := matches[2]
-
-	if caption, ok := strings.CutPrefix(alt, "figure:"); ok {
-		caption = strings.TrimSpace(caption)
-		escapedCaption := escapeHTML(caption)
-		return fmt.Sprintf("<figure>\n  %s\n  <figcaption>%s</figcaption>\n</figure>", renderHTMLImage(src, caption), escapedCaption), true
-	}
-
-	return renderHTMLImage(src, alt), true
-}
-
-func renderHTMLImage(src, alt string) string {
-	return fmt.Sprintf("<img src=\"%s\" alt=\"%s\">", escapeHTML(src), escapeHTML(alt))
-}
-
-func processBlockQuote(line string) string {
-	content := strings.TrimSpace(strings.TrimPrefix(line, ">"))
-	label, rest, isCallout := splitBlockQuoteCallout(content)
-	if !isCallout {
-		return fmt.Sprintf("<blockquote>%s</blockquote>", processInlineElements(content))
-	}
-
-	processedLabel := processInlineElements(label)
-	processedRest := processInlineElements(strings.TrimSpace(rest))
-	if processedRest == "" {
-		return fmt.Sprintf("<blockquote><strong>%s</strong></blockquote>", processedLabel)
-	}
-
-	return fmt.Sprintf("<blockquote><strong>%s</strong> %s</blockquote>", processedLabel, processedRest)
-}
-
-func splitBlockQuoteCallout(content string) (string, string, bool) {
-	colonIdx := findBlockQuoteCalloutBoundary(content)
-	if colonIdx < 0 {
-		return "", "", false
-	}
-
-	label := content[:colonIdx+1]
-	return label, content[colonIdx+1:], true
-}
-
-func findBlockQuoteCalloutBoundary(content string) int {
-	colonIdx := strings.Index(content, ":")
-	if colonIdx < 0 {
-		return -1
-	}
-
-	if strings.ContainsAny(content[:colonIdx], ".,;-") {
-		return -1
-	}
-
-	return colonIdx
-}
-
-func processInlineElements(text string) string {
-	// Process inline elements BEFORE escaping HTML
-
-	// Inline code - extract and protect from escaping
-	codeMap := make(map[string]string)
-	codeCounter := 0
-	re := regexp.MustCompile("`([^`]+)`")
-	text = re.ReplaceAllStringFunc(text, func(match string) string {
-		code := strings.Trim(match, "`")
-		placeholder := fmt.Sprintf("__CODE_PLACEHOLDER_%d__", codeCounter)
-		codeMap[placeholder] = fmt.Sprintf("<code>%s</code>", escapeHTML(code))
-		codeCounter++
-		return placeholder
-	})
-
-	// Bold text - **text**
-	boldMap := make(map[string]string)
-	boldCounter := 0
-	re = regexp.MustCompile(`\*\*([^*]+)\*\*`)
-	text = re.ReplaceAllStringFunc(text, func(match string) string {
-		content := strings.Trim(match, "*")
-		placeholder := fmt.Sprintf("__BOLD_PLACEHOLDER_%d__", boldCounter)
-		boldMap[placeholder] = fmt.Sprintf("<strong>%s</strong>", content)
-		boldCounter++
-		return placeholder
-	})
-
-	// Italic text - *text*
-	italicMap := make(map[string]string)
-	italicCounter := 0
-	re = regexp.MustCompile(`\*([^*]+)\*`)
-	text = re.ReplaceAllStringFunc(text, func(match string) string {
-		content := strings.Trim(match, "*")
-		placeholder := fmt.Sprintf("__ITALIC_PLACEHOLDER_%d__", italicCounter)
-		italicMap[placeholder] = fmt.Sprintf("<em>%s</em>", content)
-		italicCounter++
-		return placeholder
-	})
-
-	// Links (before auto-links to avoid double processing)
-	linkMap := make(map[string]string)
-	linkCounter := 0
-	re = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
-	text = re.ReplaceAllStringFunc(text, func(match string) string {
-		parts := re.FindStringSubmatch(match)
-		if len(parts) >= 3 {
-			placeholder := fmt.Sprintf("__LINK_PLACEHOLDER_%d__", linkCounter)
-			linkMap[placeholder] = fmt.Sprintf("<a href=\"%s\">%s</a>", escapeHTML(parts[2]), escapeHTML(parts[1]))
-			linkCounter++
-			return placeholder
-		}
-		return match
-	})
-
-	// Auto-links (standalone URLs)
-	re = regexp.MustCompile(`(https?://[^\s\)<]+)`)
-	text = re.ReplaceAllStringFunc(text, func(match string) string {
-		// Don't replace if it's already in a placeholder (already processed as a link)
-		if strings.Contains(match, "PLACEHOLDER") {
-			return match
-		}
-		placeholder := fmt.Sprintf("__AUTOLINK_PLACEHOLDER_%d__", linkCounter)
-		linkMap[placeholder] = fmt.Sprintf("<a href=\"%s\">%s</a>", escapeHTML(match), escapeHTML(match))
-		linkCounter++
-		return placeholder
-	})
-
-	// Now escape the remaining HTML in regular text
-	text = escapeHTML(text)
-
-	// Restore bold placeholders
-	for placeholder, boldHTML := range boldMap {
-		text = strings.ReplaceAll(text, placeholder, boldHTML)
-	}
-
-	// Restore italic placeholders
-	for placeholder, italicHTML := range italicMap {
-		text = strings.ReplaceAll(text, placeholder, italicHTML)
-	}
-
-	// Restore link placeholders
-	for placeholder, linkHTML := range linkMap {
-		text = strings.ReplaceAll(text, placeholder, linkHTML)
-	}
-
-	// Restore code placeholders last (they can be nested inside bold/italic)
-	for placeholder, codeHTML := range codeMap {
-		text = strings.ReplaceAll(text, placeholder, codeHTML)
-	}
-
-	return text
-}
-
-func escapeHTML(text string) string {
-	text = strings.ReplaceAll(text, "&", "&amp;")
-	text = strings.ReplaceAll(text, "<", "&lt;")
-	text = strings.ReplaceAll(text, ">", "&gt;")
-	text = strings.ReplaceAll(text, "\"", "&quot;")
-	text = strings.ReplaceAll(text, "'", "&#39;")
-	return text
-}
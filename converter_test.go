@@ -21,6 +21,11 @@ type multilineTestCase struct {
 
 const indentHtmlWith4Spaces = "    "
 
+// convertSingleLine renders a one-line document without the trailing block separator
+func convertSingleLine(markdown string) string {
+	return strings.TrimSuffix(GenerateHtmlBody(markdown), "\n")
+}
+
 func (tt multilineTestCase) toString(indentation string) (string, string) {
 	expected := strings.ReplaceAll(strings.Join(tt.expected, "\n"), "•", indentation)
 	markdown := strings.Join(tt.markdown, "\n")
@@ -49,24 +54,1549 @@ func TestSimpleConversion(t *testing.T) {
 			expected: "\n",
 		},
 		{
-			name:     "02 Random Single line Text",
-			markdown: "Lorem impsum dolor sit amet.",
-			expected: "<p>Lorem impsum dolor sit amet.</p>\n",
+			name:     "02 Random Single line Text",
+			markdown: "Lorem impsum dolor sit amet.",
+			expected: "<p>Lorem impsum dolor sit amet.</p>\n",
+		},
+		{
+			name:     "03 Two paragraphs",
+			markdown: "Paragraph One.\n\nParagraph Two.",
+			expected: "<p>Paragraph One.</p>\n\n<p>Paragraph Two.</p>\n",
+		},
+		{
+			name:     "04 Only whitespace",
+			markdown: "   \n\n   ",
+			expected: "\n",
+		},
+		{
+			name:     "05 Only newlines",
+			markdown: "\n\n\n",
+			expected: "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, GenerateHtmlBody(tt.markdown), tt.expected)
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Paragraphs and line breaks
+// ---------------------------------------------------------------------------
+
+func TestParagraphConversion(t *testing.T) {
+	tests := []multilineTestCase{
+		{
+			name: "01 Wrapped lines join into one paragraph",
+			markdown: []string{
+				"Most of our authors",
+				"hard-wrap their prose",
+				"at 80 columns."},
+			expected: []string{
+				"<p>Most of our authors",
+				"hard-wrap their prose",
+				"at 80 columns.</p>",
+				""},
+		},
+		{
+			name: "02 Blank line ends the paragraph",
+			markdown: []string{
+				"First paragraph",
+				"continues here.",
+				"",
+				"Second paragraph."},
+			expected: []string{
+				"<p>First paragraph",
+				"continues here.</p>",
+				"",
+				"<p>Second paragraph.</p>",
+				""},
+		},
+		{
+			name: "03 Two trailing spaces produce a hard break",
+			markdown: []string{
+				"Roses are red,  ",
+				"violets are blue."},
+			expected: []string{
+				"<p>Roses are red,<br>",
+				"violets are blue.</p>",
+				""},
+		},
+		{
+			name: "04 Trailing backslash produces a hard break",
+			markdown: []string{
+				"Roses are red,\\",
+				"violets are blue."},
+			expected: []string{
+				"<p>Roses are red,<br>",
+				"violets are blue.</p>",
+				""},
+		},
+		{
+			name: "05 Single trailing space stays a soft break",
+			markdown: []string{
+				"Roses are red, ",
+				"violets are blue."},
+			expected: []string{
+				"<p>Roses are red,",
+				"violets are blue.</p>",
+				""},
+		},
+		{
+			name: "06 Trailing spaces at the end of a paragraph are dropped",
+			markdown: []string{
+				"Last line  ",
+				"",
+				"Next"},
+			expected: []string{
+				"<p>Last line</p>",
+				"",
+				"<p>Next</p>",
+				""},
+		},
+		{
+			name: "07 Whitespace-only line ends the paragraph",
+			markdown: []string{
+				"First",
+				"   ",
+				"Second"},
+			expected: []string{
+				"<p>First</p>",
+				"",
+				"<p>Second</p>",
+				""},
+		},
+		{
+			name: "08 Heading, list and quote interrupt a paragraph",
+			markdown: []string{
+				"Intro text",
+				"# Heading",
+				"Text before list",
+				"- item",
+				"Text before quote",
+				"> quote"},
+			expected: []string{
+				"<p>Intro text</p>",
+				"<h1 id=\"heading\">Heading</h1>",
+				"<p>Text before list</p>",
+				"<ul>",
+				"•<li>item</li>",
+				"</ul>",
+				"<p>Text before quote</p>",
+				"<blockquote>quote</blockquote>",
+				""},
+		},
+		{
+			name: "09 Inline formatting spans wrapped lines",
+			markdown: []string{
+				"Some **bold",
+				"text** and `code",
+				"span`."},
+			expected: []string{
+				"<p>Some <strong>bold",
+				"text</strong> and <code>code span</code>.</p>",
+				""},
+		},
+		{
+			name: "10 Indented continuation line joins the list item",
+			markdown: []string{
+				"- first line  ",
+				"  second line",
+				"- next item"},
+			expected: []string{
+				"<ul>",
+				"•<li>first line<br>",
+				"second line</li>",
+				"•<li>next item</li>",
+				"</ul>",
+				""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, markdown := tt.toString(indentHtmlWith4Spaces)
+			td.Cmp(t, GenerateHtmlBody(markdown), expected)
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Headers
+// ---------------------------------------------------------------------------
+
+func TestHeaderConversion(t *testing.T) {
+	tests := []simpleTestCase{
+		{
+			name:     "01 H1 header",
+			markdown: "# Main Title",
+			expected: "<h1 id=\"main-title\">Main Title</h1>",
+		},
+		{
+			name:     "02 H2 header",
+			markdown: "## Subtitle",
+			expected: "<h2 id=\"subtitle\">Subtitle</h2>",
+		},
+		{
+			name:     "03 H3 header",
+			markdown: "### Sub Subtitle",
+			expected: "<h3 id=\"sub-subtitle\">Sub Subtitle</h3>",
+		},
+		{
+			name:     "04 H1 with inline code",
+			markdown: "# Title with `code`",
+			expected: "<h1 id=\"title-with-code\">Title with <code>code</code></h1>",
+		},
+		{
+			name:     "05 H2 with link",
+			markdown: "## See [docs](https://example.com)",
+			expected: "<h2 id=\"see-docs\">See <a href=\"https://example.com\">docs</a></h2>",
+		},
+		{
+			name:     "06 Bold text in header",
+			markdown: "#### Title with **bold** text",
+			expected: "<h4 id=\"title-with-bold-text\">Title with <strong>bold</strong> text</h4>",
+		},
+		{
+			name:     "07 Italic text in paragraph",
+			markdown: "#### Title with *italic* text",
+			expected: "<h4 id=\"title-with-italic-text\">Title with <em>italic</em> text</h4>",
+		},
+		{
+			name:     "08 H5 header",
+			markdown: "##### Fifth level",
+			expected: "<h5 id=\"fifth-level\">Fifth level</h5>",
+		},
+		{
+			name:     "09 H6 header",
+			markdown: "###### Sixth level",
+			expected: "<h6 id=\"sixth-level\">Sixth level</h6>",
+		},
+		{
+			name:     "10 Seven hashes are not a header",
+			markdown: "####### Too deep",
+			expected: "<p>####### Too deep</p>",
+		},
+		{
+			name:     "11 Closing hashes are removed",
+			markdown: "## Closed heading ###",
+			expected: "<h2 id=\"closed-heading\">Closed heading</h2>",
+		},
+		{
+			name:     "12 Hash without a space before it stays in the text",
+			markdown: "## Learn C#",
+			expected: "<h2 id=\"learn-c\">Learn C#</h2>",
+		},
+		{
+			name:     "13 Hash without a following space is not a header",
+			markdown: "#hashtag",
+			expected: "<p>#hashtag</p>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, convertSingleLine(tt.markdown), tt.expected)
+		})
+	}
+}
+
+func TestSetextHeadingConversion(t *testing.T) {
+	tests := []multilineTestCase{
+		{
+			name: "01 Equals underline produces h1",
+			markdown: []string{
+				"Title",
+				"====="},
+			expected: []string{
+				"<h1 id=\"title\">Title</h1>",
+				""},
+		},
+		{
+			name: "02 Dash underline produces h2",
+			markdown: []string{
+				"Subtitle",
+				"--"},
+			expected: []string{
+				"<h2 id=\"subtitle\">Subtitle</h2>",
+				""},
+		},
+		{
+			name: "03 Wrapped lines form one heading",
+			markdown: []string{
+				"Long **title**",
+				"wrapped",
+				"==="},
+			expected: []string{
+				"<h1 id=\"long-title-wrapped\">Long <strong>title</strong>",
+				"wrapped</h1>",
+				""},
+		},
+		{
+			name: "04 Underline after front matter",
+			markdown: []string{
+				"---",
+				"title: Page",
+				"---",
+				"Chapter",
+				"---",
+				"Text"},
+			expected: []string{
+				"<h2 id=\"chapter\">Chapter</h2>",
+				"<p>Text</p>",
+				""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, markdown := tt.toString(indentHtmlWith4Spaces)
+			td.Cmp(t, GenerateHtmlBody(markdown), expected)
+		})
+	}
+}
+
+func TestHeadingIDs(t *testing.T) {
+	tests := []multilineTestCase{
+		{
+			name: "01 Polish heading gets a transliterated slug",
+			markdown: []string{
+				"## Fragmentacja Intencji"},
+			expected: []string{
+				"<h2 id=\"fragmentacja-intencji\">Fragmentacja Intencji</h2>",
+				""},
+		},
+		{
+			name: "02 Slug uses text without inline markup",
+			markdown: []string{
+				"## Using `TBytes` with **helpers**"},
+			expected: []string{
+				"<h2 id=\"using-tbytes-with-helpers\">Using <code>TBytes</code> with <strong>helpers</strong></h2>",
+				""},
+		},
+		{
+			name: "03 Duplicate headings get a numeric suffix",
+			markdown: []string{
+				"## Example",
+				"## Example",
+				"### Example"},
+			expected: []string{
+				"<h2 id=\"example\">Example</h2>",
+				"<h2 id=\"example-2\">Example</h2>",
+				"<h3 id=\"example-3\">Example</h3>",
+				""},
+		},
+		{
+			name: "04 Headings inside quotes share the same id space",
+			markdown: []string{
+				"# Summary",
+				"",
+				"> # Summary"},
+			expected: []string{
+				"<h1 id=\"summary\">Summary</h1>",
+				"",
+				"<blockquote>",
+				"<h1 id=\"summary-2\">Summary</h1>",
+				"</blockquote>",
+				""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, markdown := tt.toString(indentHtmlWith4Spaces)
+			td.Cmp(t, GenerateHtmlBody(markdown), expected)
+		})
+	}
+}
+
+func TestHeadingAnchorsOption(t *testing.T) {
+	result, err := ConvertMarkdownToHTMLWithOptions("## Co Dają Class Helpers", "{{ .Content }}", "", ConvertOptions{HeadingAnchors: true})
+
+	td.Cmp(t, err, nil)
+	td.Cmp(t, result, "<h2 id=\"co-daja-class-helpers\"><a class=\"anchor\" href=\"#co-daja-class-helpers\" aria-hidden=\"true\">#</a>Co Dają Class Helpers</h2>\n")
+}
+
+// ---------------------------------------------------------------------------
+// Thematic breaks
+// ---------------------------------------------------------------------------
+
+func TestThematicBreakConversion(t *testing.T) {
+	tests := []multilineTestCase{
+		{
+			name: "01 Dashes, asterisks and underscores",
+			markdown: []string{
+				"---",
+				"",
+				"***",
+				"",
+				"_ _ _"},
+			expected: []string{
+				"<hr>",
+				"",
+				"<hr>",
+				"",
+				"<hr>",
+				""},
+		},
+		{
+			name: "02 Asterisks interrupt a paragraph",
+			markdown: []string{
+				"End of section",
+				"***",
+				"Next section"},
+			expected: []string{
+				"<p>End of section</p>",
+				"<hr>",
+				"<p>Next section</p>",
+				""},
+		},
+		{
+			name: "03 Dashes below a paragraph make a setext heading",
+			markdown: []string{
+				"Section",
+				"---"},
+			expected: []string{
+				"<h2 id=\"section\">Section</h2>",
+				""},
+		},
+		{
+			name: "04 Spaced dashes are not a list item",
+			markdown: []string{
+				"- item",
+				"- - -"},
+			expected: []string{
+				"<ul>",
+				"•<li>item</li>",
+				"</ul>",
+				"<hr>",
+				""},
+		},
+		{
+			name: "05 Front matter delimiters are not rendered",
+			markdown: []string{
+				"---",
+				"title: Page",
+				"---",
+				"Text",
+				"",
+				"---"},
+			expected: []string{
+				"<p>Text</p>",
+				"",
+				"<hr>",
+				""},
+		},
+		{
+			name: "06 Two characters are plain text",
+			markdown: []string{
+				"**"},
+			expected: []string{
+				"<p>**</p>",
+				""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, markdown := tt.toString(indentHtmlWith4Spaces)
+			td.Cmp(t, GenerateHtmlBody(markdown), expected)
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Footnotes
+// ---------------------------------------------------------------------------
+
+func TestFootnoteConversion(t *testing.T) {
+	tests := []multilineTestCase{
+		{
+			name: "01 Reference and definition",
+			markdown: []string{
+				"Source needed[^src].",
+				"",
+				"[^src]: Delphi documentation."},
+			expected: []string{
+				"<p>Source needed<sup class=\"footnote-ref\"><a href=\"#fn-1\" id=\"fnref-1\">1</a></sup>.</p>",
+				"",
+				"<section class=\"footnotes\">",
+				"<ol>",
+				"•<li id=\"fn-1\">",
+				"<p>Delphi documentation. <a href=\"#fnref-1\" class=\"footnote-backref\" aria-label=\"Back to reference 1\">↩</a></p>",
+				"•</li>",
+				"</ol>",
+				"</section>",
+				""},
+		},
+		{
+			name: "02 Numbered by first reference, not by definition order",
+			markdown: []string{
+				"[^b]: Second defined.",
+				"[^a]: First defined.",
+				"",
+				"One[^a] two[^b]."},
+			expected: []string{
+				"<p>One<sup class=\"footnote-ref\"><a href=\"#fn-1\" id=\"fnref-1\">1</a></sup> two<sup class=\"footnote-ref\"><a href=\"#fn-2\" id=\"fnref-2\">2</a></sup>.</p>",
+				"<section class=\"footnotes\">",
+				"<ol>",
+				"•<li id=\"fn-1\">",
+				"<p>First defined. <a href=\"#fnref-1\" class=\"footnote-backref\" aria-label=\"Back to reference 1\">↩</a></p>",
+				"•</li>",
+				"•<li id=\"fn-2\">",
+				"<p>Second defined. <a href=\"#fnref-2\" class=\"footnote-backref\" aria-label=\"Back to reference 2\">↩</a></p>",
+				"•</li>",
+				"</ol>",
+				"</section>",
+				""},
+		},
+		{
+			name: "03 Multi-paragraph definition with repeated reference",
+			markdown: []string{
+				"A[^1] and B[^1].",
+				"",
+				"[^1]: First paragraph.",
+				"",
+				"    Second paragraph."},
+			expected: []string{
+				"<p>A<sup class=\"footnote-ref\"><a href=\"#fn-1\" id=\"fnref-1\">1</a></sup> and B<sup class=\"footnote-ref\"><a href=\"#fn-1\" id=\"fnref-1-2\">1</a></sup>.</p>",
+				"",
+				"<section class=\"footnotes\">",
+				"<ol>",
+				"•<li id=\"fn-1\">",
+				"<p>First paragraph.</p>",
+				"",
+				"<p>Second paragraph. <a href=\"#fnref-1\" class=\"footnote-backref\" aria-label=\"Back to reference 1\">↩</a> <a href=\"#fnref-1-2\" class=\"footnote-backref\" aria-label=\"Back to reference 1\">↩<sup>2</sup></a></p>",
+				"•</li>",
+				"</ol>",
+				"</section>",
+				""},
+		},
+		{
+			name: "04 Undefined reference stays literal",
+			markdown: []string{
+				"Missing[^x]."},
+			expected: []string{
+				"<p>Missing[^x].</p>",
+				""},
+		},
+		{
+			name: "05 Unreferenced definition is dropped",
+			markdown: []string{
+				"Text",
+				"",
+				"[^unused]: Never cited."},
+			expected: []string{
+				"<p>Text</p>",
+				"",
+				""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, markdown := tt.toString(indentHtmlWith4Spaces)
+			td.Cmp(t, GenerateHtmlBody(markdown), expected)
+		})
+	}
+}
+
+func TestTemplateFootnotes(t *testing.T) {
+	markdown := "Cited[^1].\n\n[^1]: Source."
+
+	result, err := ConvertMarkdownToHTML(markdown, "<aside>{{ .Footnotes }}</aside>", "")
+
+	td.Cmp(t, err, nil)
+	td.Cmp(t, result, `<aside><section class="footnotes">
+<ol>
+    <li id="fn-1">
+<p>Source. <a href="#fnref-1" class="footnote-backref" aria-label="Back to reference 1">↩</a></p>
+    </li>
+</ol>
+</section>
+</aside>`)
+}
+
+// ---------------------------------------------------------------------------
+// Table of contents
+// ---------------------------------------------------------------------------
+
+func TestTableOfContents(t *testing.T) {
+	tests := []multilineTestCase{
+		{
+			name: "01 Nested headings",
+			markdown: []string{
+				"# Title",
+				"## First",
+				"### Details",
+				"## Second"},
+			expected: []string{
+				"<nav class=\"toc\">",
+				"<ul>",
+				"•<li><a href=\"#title\">Title</a>",
+				"••<ul>",
+				"•••<li><a href=\"#first\">First</a>",
+				"••••<ul>",
+				"•••••<li><a href=\"#details\">Details</a></li>",
+				"••••</ul>",
+				"•••</li>",
+				"•••<li><a href=\"#second\">Second</a></li>",
+				"••</ul>",
+				"•</li>",
+				"</ul>",
+				"</nav>",
+				""},
+		},
+		{
+			name: "02 Levels limited by front matter",
+			markdown: []string{
+				"---",
+				"tocLevels: 2-3",
+				"---",
+				"# Title",
+				"## First",
+				"#### Hidden",
+				"## Second"},
+			expected: []string{
+				"<nav class=\"toc\">",
+				"<ul>",
+				"•<li><a href=\"#first\">First</a></li>",
+				"•<li><a href=\"#second\">Second</a></li>",
+				"</ul>",
+				"</nav>",
+				""},
+		},
+		{
+			name: "03 No headings",
+			markdown: []string{
+				"Just a paragraph."},
+			expected: []string{
+				""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, markdown := tt.toString(indentHtmlWith4Spaces)
+			result, err := ConvertMarkdownToHTML(markdown, "{{ .TOC }}", "")
+			td.Cmp(t, err, nil)
+			td.Cmp(t, result, expected)
+		})
+	}
+}
+
+func TestTemplateHeadings(t *testing.T) {
+	markdown := "# Title\n## Co Dają `Class` Helpers\n## Title"
+
+	result, err := ConvertMarkdownToHTML(markdown, "{{ range .Headings }}{{ .Level }} {{ .ID }} {{ .Text }};{{ end }}", "")
+
+	td.Cmp(t, err, nil)
+	td.Cmp(t, result, "1 title Title;2 co-daja-class-helpers Co Dają Class Helpers;2 title-2 Title;")
+}
+
+func TestTocMarker(t *testing.T) {
+	markdown := "# Title\n\n[[toc]]\n\n## Section"
+
+	result := GenerateHtmlBody(markdown)
+
+	td.Cmp(t, result, `<h1 id="title">Title</h1>
+
+<nav class="toc">
+<ul>
+    <li><a href="#title">Title</a>
+        <ul>
+            <li><a href="#section">Section</a></li>
+        </ul>
+    </li>
+</ul>
+</nav>
+
+<h2 id="section">Section</h2>
+`)
+}
+
+func TestParseTocLevels(t *testing.T) {
+	tests := []struct {
+		value    string
+		minLevel int
+		maxLevel int
+	}{
+		{value: "", minLevel: 1, maxLevel: 6},
+		{value: "2-3", minLevel: 2, maxLevel: 3},
+		{value: "2", minLevel: 2, maxLevel: 2},
+		{value: "3-2", minLevel: 1, maxLevel: 6},
+		{value: "0-9", minLevel: 1, maxLevel: 6},
+		{value: "abc", minLevel: 1, maxLevel: 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			minLevel, maxLevel := parseTocLevels(tt.value)
+			td.Cmp(t, minLevel, tt.minLevel)
+			td.Cmp(t, maxLevel, tt.maxLevel)
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Block Quote conversions
+// ---------------------------------------------------------------------------
+
+func TestBlockQuoteConversion(t *testing.T) {
+	tests := []simpleTestCase{
+		{
+			name:     "01 Basic Block Quote",
+			markdown: "> Lorem impsum dolor sit amet.",
+			expected: "<blockquote>Lorem impsum dolor sit amet.</blockquote>\n",
+		},
+		{
+			name:     "02 Block Quote with Callout",
+			markdown: "> Rule of thumb: Lorem impsum dolor sit amet.",
+			expected: "<blockquote><strong>Rule of thumb:</strong> Lorem impsum dolor sit amet.</blockquote>\n",
+		},
+		{
+			name:     "03 Callout ignored after comma",
+			markdown: "> Rule of thumb, actually: Lorem impsum dolor sit amet.",
+			expected: "<blockquote>Rule of thumb, actually: Lorem impsum dolor sit amet.</blockquote>\n",
+		},
+		{
+			name:     "04 Callout ignored after period",
+			markdown: "> Rule of thumb. Actually: Lorem impsum dolor sit amet.",
+			expected: "<blockquote>Rule of thumb. Actually: Lorem impsum dolor sit amet.</blockquote>\n",
+		},
+		{
+			name:     "05 Callout ignored after semicolon",
+			markdown: "> Rule of thumb; actually: Lorem impsum dolor sit amet.",
+			expected: "<blockquote>Rule of thumb; actually: Lorem impsum dolor sit amet.</blockquote>\n",
+		},
+		{
+			name:     "06 Callout ignored after hyphen",
+			markdown: "> Rule of thumb - actually: Lorem impsum dolor sit amet.",
+			expected: "<blockquote>Rule of thumb - actually: Lorem impsum dolor sit amet.</blockquote>\n",
+		},
+		{
+			name:     "07 Block Quote preserves inline formatting",
+			markdown: "> Use **bold**, *italic*, `code`, and [link](https://example.com)",
+			expected: "<blockquote>Use <strong>bold</strong>, <em>italic</em>, <code>code</code>, and <a href=\"https://example.com\">link</a></blockquote>\n",
+		},
+		{
+			name:     "08 Up to three spaces before the marker",
+			markdown: "   > Indented quote",
+			expected: "<blockquote>Indented quote</blockquote>\n",
+		},
+		{
+			name:     "09 Non-breaking space before the marker is text",
+			markdown: "Tekst\n\n\u00a0> cytat",
+			expected: "<p>Tekst</p>\n\n<p>\u00a0&gt; cytat</p>\n",
+		},
+		{
+			name:     "10 Carriage return before the marker is text",
+			markdown: "\r>x",
+			expected: "<p>\r&gt;x</p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, GenerateHtmlBody(tt.markdown), tt.expected)
+		})
+	}
+}
+
+func TestMultilineBlockQuoteConversion(t *testing.T) {
+	tests := []multilineTestCase{
+		{
+			name: "01 Consecutive quote lines merge into one quote",
+			markdown: []string{
+				"> First line",
+				"> second line"},
+			expected: []string{
+				"<blockquote>First line",
+				"second line</blockquote>",
+				""},
+		},
+		{
+			name: "02 Double marker nests a quote",
+			markdown: []string{
+				"> Outer",
+				">> Inner"},
+			expected: []string{
+				"<blockquote>",
+				"<p>Outer</p>",
+				"<blockquote>Inner</blockquote>",
+				"</blockquote>",
+				""},
+		},
+		{
+			name: "03 Quote with paragraphs, list and code",
+			markdown: []string{
+				"> Intro paragraph.",
+				">",
+				"> - one",
+				"> - two",
+				">",
+				"> ```go",
+				"> x := 1",
+				"> ```"},
+			expected: []string{
+				"<blockquote>",
+				"<p>Intro paragraph.</p>",
+				"",
+				"<ul>",
+				"•<li>one</li>",
+				"•<li>two",
+				"••<div class=\"code\" data-language=\"go\">",
+				"••<pre><code>x := 1</code></pre>",
+				"••</div>",
+				"•</li>",
+				"</ul>",
+				"</blockquote>",
+				""},
+		},
+		{
+			name: "04 Quote with heading and image",
+			markdown: []string{
+				"> ## Quoted heading",
+				"> ![Tux](tux.png)"},
+			expected: []string{
+				"<blockquote>",
+				"<h2 id=\"quoted-heading\">Quoted heading</h2>",
+				"<img src=\"tux.png\" alt=\"Tux\">",
+				"</blockquote>",
+				""},
+		},
+		{
+			name: "05 Callout applies to the first paragraph of a merged quote",
+			markdown: []string{
+				"> Rule of thumb: keep helpers small",
+				"> and focused.",
+				">",
+				"> Second paragraph."},
+			expected: []string{
+				"<blockquote>",
+				"<p><strong>Rule of thumb:</strong> keep helpers small",
+				"and focused.</p>",
+				"",
+				"<p>Second paragraph.</p>",
+				"</blockquote>",
+				""},
+		},
+		{
+			name: "06 Callout label alone before a list",
+			markdown: []string{
+				"> Checklist:",
+				"> - tests",
+				"> - docs"},
+			expected: []string{
+				"<blockquote>",
+				"<p><strong>Checklist:</strong></p>",
+				"<ul>",
+				"•<li>tests</li>",
+				"•<li>docs</li>",
+				"</ul>",
+				"</blockquote>",
+				""},
+		},
+		{
+			name: "07 Colon on a later line is not a callout",
+			markdown: []string{
+				"> Plain first line",
+				"> Label: second line"},
+			expected: []string{
+				"<blockquote>Plain first line",
+				"Label: second line</blockquote>",
+				""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, markdown := tt.toString(indentHtmlWith4Spaces)
+			td.Cmp(t, GenerateHtmlBody(markdown), expected)
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Admonitions
+// ---------------------------------------------------------------------------
+
+func TestAdmonitionConversion(t *testing.T) {
+	tests := []multilineTestCase{
+		{
+			name: "01 Note with multi-line body",
+			markdown: []string{
+				"> [!NOTE]",
+				"> Helpers are resolved",
+				"> at compile time."},
+			expected: []string{
+				"<div class=\"admonition note\">",
+				"<p class=\"admonition-title\">Note</p>",
+				"<p>Helpers are resolved",
+				"at compile time.</p>",
+				"</div>",
+				""},
+		},
+		{
+			name: "02 All GitHub kinds are recognized",
+			markdown: []string{
+				"> [!TIP]",
+				"> a",
+				"",
+				"> [!IMPORTANT]",
+				"> b",
+				"",
+				"> [!WARNING]",
+				"> c",
+				"",
+				"> [!CAUTION]",
+				"> d"},
+			expected: []string{
+				"<div class=\"admonition tip\">",
+				"<p class=\"admonition-title\">Tip</p>",
+				"<p>a</p>",
+				"</div>",
+				"",
+				"<div class=\"admonition important\">",
+				"<p class=\"admonition-title\">Important</p>",
+				"<p>b</p>",
+				"</div>",
+				"",
+				"<div class=\"admonition warning\">",
+				"<p class=\"admonition-title\">Warning</p>",
+				"<p>c</p>",
+				"</div>",
+				"",
+				"<div class=\"admonition caution\">",
+				"<p class=\"admonition-title\">Caution</p>",
+				"<p>d</p>",
+				"</div>",
+				""},
+		},
+		{
+			name: "03 Default title follows the front matter language",
+			markdown: []string{
+				"---",
+				"language: pl",
+				"---",
+				"> [!WARNING]",
+				"> Nie usuwaj tego pliku."},
+			expected: []string{
+				"<div class=\"admonition warning\">",
+				"<p class=\"admonition-title\">Uwaga</p>",
+				"<p>Nie usuwaj tego pliku.</p>",
+				"</div>",
+				""},
+		},
+		{
+			name: "04 Custom title after the marker",
+			markdown: []string{
+				"> [!TIP] Use *helpers*",
+				"> Body"},
+			expected: []string{
+				"<div class=\"admonition tip\">",
+				"<p class=\"admonition-title\">Use <em>helpers</em></p>",
+				"<p>Body</p>",
+				"</div>",
+				""},
+		},
+		{
+			name: "05 Body keeps block content",
+			markdown: []string{
+				"> [!IMPORTANT]",
+				"> Steps:",
+				"> 1. Build",
+				"> 2. Test"},
+			expected: []string{
+				"<div class=\"admonition important\">",
+				"<p class=\"admonition-title\">Important</p>",
+				"<p>Steps:</p>",
+				"<ol>",
+				"•<li>Build</li>",
+				"•<li>Test</li>",
+				"</ol>",
+				"</div>",
+				""},
+		},
+		{
+			name: "06 Unknown kind stays a block quote",
+			markdown: []string{
+				"> [!UNKNOWN]",
+				"> text"},
+			expected: []string{
+				"<blockquote>[!UNKNOWN]",
+				"text</blockquote>",
+				""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, markdown := tt.toString(indentHtmlWith4Spaces)
+			td.Cmp(t, GenerateHtmlBody(markdown), expected)
+		})
+	}
+}
+
+func TestRegisterCustomAdmonition(t *testing.T) {
+	RegisterAdmonition("DANGER", map[string]string{"en": "Danger", "pl": "Niebezpieczeństwo"})
+	t.Cleanup(func() { delete(admonitionRegistry, "danger") })
+
+	markdown := "---\nlanguage: pl-PL\n---\n> [!DANGER]\n> Wysokie napięcie"
+
+	td.Cmp(t, GenerateHtmlBody(markdown), strings.Join([]string{
+		"<div class=\"admonition danger\">",
+		"<p class=\"admonition-title\">Niebezpieczeństwo</p>",
+		"<p>Wysokie napięcie</p>",
+		"</div>",
+		""}, "\n"))
+}
+
+// ---------------------------------------------------------------------------
+// Emphasis, escapes and code spans
+// ---------------------------------------------------------------------------
+
+func TestInlineParsing(t *testing.T) {
+	tests := []simpleTestCase{
+		{
+			name:     "01 Italic nested in bold",
+			markdown: "**bold *italic***",
+			expected: "<p><strong>bold <em>italic</em></strong></p>",
+		},
+		{
+			name:     "02 Bold nested in italic",
+			markdown: "*a **b** c*",
+			expected: "<p><em>a <strong>b</strong> c</em></p>",
+		},
+		{
+			name:     "03 Triple delimiters",
+			markdown: "***both***",
+			expected: "<p><em><strong>both</strong></em></p>",
+		},
+		{
+			name:     "04 Underscore emphasis",
+			markdown: "_italic_ and __bold__",
+			expected: "<p><em>italic</em> and <strong>bold</strong></p>",
+		},
+		{
+			name:     "05 Underscores inside words stay literal",
+			markdown: "snake_case_name and 2*3*4",
+			expected: "<p>snake_case_name and 2<em>3</em>4</p>",
+		},
+		{
+			name:     "06 Unmatched delimiters stay literal",
+			markdown: "**unclosed and *also",
+			expected: "<p>**unclosed and *also</p>",
+		},
+		{
+			name:     "07 Placeholder-like text is ordinary text",
+			markdown: "__BOLD_PLACEHOLDER_0__ and __INLINE_CODE_1__",
+			expected: "<p><strong>BOLD_PLACEHOLDER_0</strong> and <strong>INLINE_CODE_1</strong></p>",
+		},
+		{
+			name:     "08 Backslash escapes",
+			markdown: "\\*not em\\* \\`tick\\` \\[not link\\] \\_x\\_ \\\\",
+			expected: "<p>*not em* `tick` [not link] _x_ \\</p>",
+		},
+		{
+			name:     "09 Backslash before a letter stays",
+			markdown: `C:\path\file`,
+			expected: `<p>C:\path\file</p>`,
+		},
+		{
+			name:     "10 Double backtick code span with a backtick inside",
+			markdown: "`` a`b ``",
+			expected: "<p><code>a`b</code></p>",
+		},
+		{
+			name:     "11 Code span keeps delimiters literal",
+			markdown: "`*not* [a](b)`",
+			expected: "<p><code>*not* [a](b)</code></p>",
+		},
+		{
+			name:     "12 Unclosed backtick is literal",
+			markdown: "a ` b",
+			expected: "<p>a ` b</p>",
+		},
+		{
+			name:     "13 Link text keeps inline formatting",
+			markdown: "[**bold** and `code`](https://example.com)",
+			expected: `<p><a href="https://example.com"><strong>bold</strong> and <code>code</code></a></p>`,
+		},
+		{
+			name:     "14 Brackets inside link text",
+			markdown: "[a [b] c](https://example.com)",
+			expected: `<p><a href="https://example.com">a [b] c</a></p>`,
+		},
+		{
+			name:     "15 Links do not nest",
+			markdown: "[outer [inner](i)](o)",
+			expected: `<p>[outer <a href="i">inner</a>](o)</p>`,
+		},
+		{
+			name:     "16 Emphasis does not cross link boundaries",
+			markdown: "*[a*](u)",
+			expected: `<p>*<a href="u">a*</a></p>`,
+		},
+		{
+			name:     "17 Image alt text is plain",
+			markdown: "Logo ![the *best* logo](l.png)",
+			expected: `<p>Logo <img src="l.png" alt="the best logo"></p>`,
+		},
+		{
+			name:     "18 Link text with URL is not autolinked twice",
+			markdown: "[https://example.com](https://example.com)",
+			expected: `<p><a href="https://example.com">https://example.com</a></p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, convertSingleLine(tt.markdown), tt.expected)
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Inline extensions
+// ---------------------------------------------------------------------------
+
+func TestInlineExtensionConversion(t *testing.T) {
+	tests := []simpleTestCase{
+		{
+			name:     "01 Strikethrough",
+			markdown: "~~old~~ new",
+			expected: "<p><del>old</del> new</p>",
+		},
+		{
+			name:     "02 Highlight with nested emphasis",
+			markdown: "==very **important**==",
+			expected: "<p><mark>very <strong>important</strong></mark></p>",
+		},
+		{
+			name:     "03 Subscript",
+			markdown: "H~2~O",
+			expected: "<p>H<sub>2</sub>O</p>",
+		},
+		{
+			name:     "04 Superscript",
+			markdown: "E = mc^2^",
+			expected: "<p>E = mc<sup>2</sup></p>",
+		},
+		{
+			name:     "05 Keyboard",
+			markdown: "Press [[Ctrl+C]] to copy",
+			expected: "<p>Press <kbd>Ctrl+C</kbd> to copy</p>",
+		},
+		{
+			name:     "06 Subscript and superscript cannot contain spaces",
+			markdown: "a~b c~ and x^y z^",
+			expected: "<p>a~b c~ and x^y z^</p>",
+		},
+		{
+			name:     "07 Unmatched markers stay literal",
+			markdown: "~~open and ==open and 2^10",
+			expected: "<p>~~open and ==open and 2^10</p>",
+		},
+		{
+			name:     "08 Longer runs are not extensions",
+			markdown: "~~~x~~~ and ===",
+			expected: "<p>~~~x~~~ and ===</p>",
+		},
+		{
+			name:     "09 Code spans keep markers literal",
+			markdown: "`~~a~~ ==b== [[c]]`",
+			expected: "<p><code>~~a~~ ==b== [[c]]</code></p>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, convertSingleLine(tt.markdown), tt.expected)
+		})
+	}
+}
+
+func TestInlineExtensionsDisabled(t *testing.T) {
+	markdown := "~~a~~ ==b== H~2~O x^2^ [[Ctrl]]"
+
+	result, err := ConvertMarkdownToHTMLWithOptions(markdown, "{{ .Content }}", "", ConvertOptions{})
+
+	td.Cmp(t, err, nil)
+	td.Cmp(t, result, "<p>~~a~~ ==b== H~2~O x^2^ [[Ctrl]]</p>\n")
+}
+
+func TestSingleInlineExtensionDisabled(t *testing.T) {
+	options := DefaultConvertOptions()
+	options.Highlight = false
+
+	result, err := ConvertMarkdownToHTMLWithOptions("~~a~~ ==b==", "{{ .Content }}", "", options)
+
+	td.Cmp(t, err, nil)
+	td.Cmp(t, result, "<p><del>a</del> ==b==</p>\n")
+}
+
+// ---------------------------------------------------------------------------
+// Inline code
+// ---------------------------------------------------------------------------
+
+func TestInlineCodeConversion(t *testing.T) {
+	tests := []simpleTestCase{
+		{
+			name:     "Inline_code_simple",
+			markdown: "`function()`",
+			expected: "<p><code>function()</code></p>",
+		},
+		{
+			name:     "Inline_code_Multiple_elements",
+			markdown: "Call function `foo()` and `bar()`",
+			expected: "<p>Call function <code>foo()</code> and <code>bar()</code></p>",
+		},
+		{
+			name:     "Inline_code_with_special_chars",
+			markdown: "Use `<tag>`",
+			expected: "<p>Use <code>&lt;tag&gt;</code></p>",
+		},
+		{
+			name:     "Inline_code_at_start",
+			markdown: "`code` at start",
+			expected: "<p><code>code</code> at start</p>",
+		},
+		{
+			name:     "Inline_code_at_end",
+			markdown: "at end `code`",
+			expected: "<p>at end <code>code</code></p>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, convertSingleLine(tt.markdown), tt.expected)
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Hyperlinks
+// ---------------------------------------------------------------------------
+
+func TestLinkConversion(t *testing.T) {
+	tests := []simpleTestCase{
+		{
+			name:     "Markdown link",
+			markdown: "[GitHub](https://github.com)",
+			expected: `<p><a href="https://github.com">GitHub</a></p>`,
+		},
+		{
+			name:     "Auto-detected HTTPS URL",
+			markdown: "Visit https://example.com to get started",
+			expected: `<p>Visit <a href="https://example.com">https://example.com</a> to get started</p>`,
+		},
+		{
+			name:     "Auto-detected HTTP URL",
+			markdown: "Go to http://example.com",
+			expected: `<p>Go to <a href="http://example.com">http://example.com</a></p>`,
+		},
+		{
+			name:     "Auto-detected URL ends before trailing punctuation",
+			markdown: "See https://example.com. Or (https://example.com/a?b=1), https://example.com/x!",
+			expected: `<p>See <a href="https://example.com">https://example.com</a>. Or (<a href="https://example.com/a?b=1">https://example.com/a?b=1</a>), <a href="https://example.com/x">https://example.com/x</a>!</p>`,
+		},
+		{
+			name:     "Auto-detected URL keeps balanced parentheses",
+			markdown: "See https://en.wikipedia.org/wiki/Delphi_(software).",
+			expected: `<p>See <a href="https://en.wikipedia.org/wiki/Delphi_(software)">https://en.wikipedia.org/wiki/Delphi_(software)</a>.</p>`,
+		},
+		{
+			name:     "URL autolink in angle brackets",
+			markdown: "Visit <https://example.com/a?b=1&c=2> or <ftp://files.example.com>",
+			expected: `<p>Visit <a href="https://example.com/a?b=1&amp;c=2">https://example.com/a?b=1&amp;c=2</a> or <a href="ftp://files.example.com">ftp://files.example.com</a></p>`,
+		},
+		{
+			name:     "Email autolink in angle brackets",
+			markdown: "Write to <bogdan.polak@example.com>",
+			expected: `<p>Write to <a href="mailto:bogdan.polak@example.com">bogdan.polak@example.com</a></p>`,
+		},
+		{
+			name:     "Angle brackets with a space are not an autolink",
+			markdown: "<https://example.com/a b>",
+			expected: `<p>&lt;<a href="https://example.com/a">https://example.com/a</a> b&gt;</p>`,
+		},
+		{
+			name:     "Multiple links",
+			markdown: "[Google](https://google.com) and [Bing](https://bing.com)",
+			expected: `<p><a href="https://google.com">Google</a> and <a href="https://bing.com">Bing</a></p>`,
+		},
+		{
+			name:     "Link with special chars in URL",
+			markdown: "[Docs](https://example.com/docs?id=123&format=html)",
+			expected: `<p><a href="https://example.com/docs?id=123&amp;format=html">Docs</a></p>`,
+		},
+		{
+			name:     "Balanced parentheses in URL",
+			markdown: "[Delphi](https://en.wikipedia.org/wiki/Delphi_(software)) IDE",
+			expected: `<p><a href="https://en.wikipedia.org/wiki/Delphi_(software)">Delphi</a> IDE</p>`,
+		},
+		{
+			name:     "Balanced parentheses with title",
+			markdown: `[Delphi](https://en.wikipedia.org/wiki/Delphi_(software) "Wiki")`,
+			expected: `<p><a href="https://en.wikipedia.org/wiki/Delphi_(software)" title="Wiki">Delphi</a></p>`,
+		},
+		{
+			name:     "Destination in angle brackets",
+			markdown: `[notes](<my notes (draft).md> "Draft") and [b](<a)b>)`,
+			expected: `<p><a href="my notes (draft).md" title="Draft">notes</a> and <a href="a)b">b</a></p>`,
+		},
+		{
+			name:     "Unbalanced parenthesis is not a link",
+			markdown: "[a](b(c)",
+			expected: `<p>[a](b(c)</p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, convertSingleLine(tt.markdown), tt.expected)
+		})
+	}
+}
+
+func TestReferenceLinkConversion(t *testing.T) {
+	tests := []multilineTestCase{
+		{
+			name: "01 Full, collapsed and shortcut references",
+			markdown: []string{
+				"See [the docs][docs], [GitHub][] and [GitHub].",
+				"",
+				"[docs]: https://example.com/docs \"Documentation\"",
+				"[github]: <https://github.com>"},
+			expected: []string{
+				"<p>See <a href=\"https://example.com/docs\" title=\"Documentation\">the docs</a>, <a href=\"https://github.com\">GitHub</a> and <a href=\"https://github.com\">GitHub</a>.</p>",
+				"",
+				""},
+		},
+		{
+			name: "02 Definition before use, removed from output",
+			markdown: []string{
+				"[home]: https://example.com 'Home page'",
+				"",
+				"Go [home]."},
+			expected: []string{
+				"<p>Go <a href=\"https://example.com\" title=\"Home page\">home</a>.</p>",
+				""},
+		},
+		{
+			name: "03 Undefined reference stays literal",
+			markdown: []string{
+				"Text [with brackets] and [link][missing]."},
+			expected: []string{
+				"<p>Text [with brackets] and [link][missing].</p>",
+				""},
+		},
+		{
+			name: "04 Labels match case-insensitively",
+			markdown: []string{
+				"[Read  More][RFC]",
+				"",
+				"[rfc]: https://example.com/rfc"},
+			expected: []string{
+				"<p><a href=\"https://example.com/rfc\">Read  More</a></p>",
+				"",
+				""},
+		},
+		{
+			name: "05 Reference images",
+			markdown: []string{
+				"![Tux][tux] and ![tux][] and ![tux]",
+				"",
+				"![figure: Linux mascot][tux]",
+				"",
+				"[tux]: /imgs/tux.png (Tux)"},
+			expected: []string{
+				"<p><img src=\"/imgs/tux.png\" alt=\"Tux\" title=\"Tux\"> and <img src=\"/imgs/tux.png\" alt=\"tux\" title=\"Tux\"> and <img src=\"/imgs/tux.png\" alt=\"tux\" title=\"Tux\"></p>",
+				"",
+				"<figure>",
+				"  <img src=\"/imgs/tux.png\" alt=\"Linux mascot\" title=\"Tux\">",
+				"  <figcaption>Linux mascot</figcaption>",
+				"</figure>",
+				"",
+				""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, markdown := tt.toString(indentHtmlWith4Spaces)
+			td.Cmp(t, GenerateHtmlBody(markdown), expected)
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Images
+// ---------------------------------------------------------------------------
+
+func TestMarkdownImageConversion(t *testing.T) {
+	tests := []simpleTestCase{
+		{
+			name:     "01 Standard markdown image renders as img tag without paragraph",
+			markdown: "![Tux, the Linux mascot](/assets/images/tux.png)",
+			expected: "<img src=\"/assets/images/tux.png\" alt=\"Tux, the Linux mascot\">\n",
+		},
+		{
+			name:     "02 Figure-prefixed alt text renders figure with figcaption",
+			markdown: "![figure: Linux mascot known as tux](/imgs/tux.png)",
+			expected: "<figure>\n  <img src=\"/imgs/tux.png\" alt=\"Linux mascot known as tux\">\n  <figcaption>Linux mascot known as tux</figcaption>\n</figure>\n",
+		},
+		{
+			name:     "03 Raw html image passes through unchanged",
+			markdown: `<img src="/assets/images/tux.png" alt="Tux, the Linux mascot">`,
+			expected: `<img src="/assets/images/tux.png" alt="Tux, the Linux mascot">` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, GenerateHtmlBody(tt.markdown), tt.expected)
+		})
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Titles and attribute blocks
+// ---------------------------------------------------------------------------
+
+func TestLinkAndImageTitles(t *testing.T) {
+	tests := []simpleTestCase{
+		{
+			name:     "01 Link with double-quoted title",
+			markdown: `[Docs](https://example.com "The Docs")`,
+			expected: `<p><a href="https://example.com" title="The Docs">Docs</a></p>`,
+		},
+		{
+			name:     "02 Link with single-quoted title",
+			markdown: `[Docs](https://example.com 'The Docs')`,
+			expected: `<p><a href="https://example.com" title="The Docs">Docs</a></p>`,
+		},
+		{
+			name:     "03 Image with parenthesized title",
+			markdown: `Logo ![Tux](/tux.png (Linux mascot))`,
+			expected: `<p>Logo <img src="/tux.png" alt="Tux" title="Linux mascot"></p>`,
+		},
+		{
+			name:     "04 Title with escaped characters",
+			markdown: `[a](b "x < y")`,
+			expected: `<p><a href="b" title="x &lt; y">a</a></p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, convertSingleLine(tt.markdown), tt.expected)
+		})
+	}
+}
+
+func TestAttributeBlocks(t *testing.T) {
+	tests := []simpleTestCase{
+		{
+			name:     "01 Heading with custom id and class",
+			markdown: "## Intro {#start .lead}",
+			expected: `<h2 id="start" class="lead">Intro</h2>`,
+		},
+		{
+			name:     "02 Setext heading with attributes",
+			markdown: "Intro {data-level=1}\n===",
+			expected: `<h1 id="intro" data-level="1">Intro</h1>`,
+		},
+		{
+			name:     "03 Image with size and class",
+			markdown: "![tux](tux.png){width=300 .float-right}",
+			expected: `<img src="tux.png" alt="tux" class="float-right" width="300">`,
+		},
+		{
+			name:     "04 Link with attributes",
+			markdown: `[docs](https://example.com){target=_blank rel="noopener noreferrer"}`,
+			expected: `<p><a href="https://example.com" target="_blank" rel="noopener noreferrer">docs</a></p>`,
+		},
+		{
+			name:     "05 Paragraph with trailing attributes",
+			markdown: "Lead text\ncontinues {.lead #intro}",
+			expected: "<p id=\"intro\" class=\"lead\">Lead text\ncontinues</p>",
+		},
+		{
+			name:     "06 Fenced code with attributes",
+			markdown: "```go {#main .numbered startFrom=10}\nfunc main() {}\n```",
+			expected: "<div id=\"main\" class=\"code numbered\" startFrom=\"10\" data-language=\"go\">\n<pre><code>func main() {}</code></pre>\n</div>",
+		},
+		{
+			name:     "07 Braces that are not attributes stay text",
+			markdown: "Use {braces} and {} here {not valid!}",
+			expected: `<p>Use {braces} and {} here {not valid!}</p>`,
+		},
+		{
+			name:     "08 Attribute block without leading space stays text",
+			markdown: "Text{.lead}",
+			expected: `<p>Text{.lead}</p>`,
+		},
+		{
+			name:     "09 Attribute values are escaped",
+			markdown: `![a](b.png){alt-text="<x>"}`,
+			expected: `<img src="b.png" alt="a" alt-text="&lt;x&gt;">`,
+		},
+		{
+			name:     "10 Image source and description cannot be replaced",
+			markdown: `![x](y.png "Title"){src=javascript:alert(1) alt=z title=t width=10}`,
+			expected: `<img src="y.png" alt="x" title="Title" width="10">`,
+		},
+		{
+			name:     "11 Link destination cannot be replaced",
+			markdown: `[x](https://example.com){HREF=javascript:alert(1) title=t}`,
+			expected: `<p><a href="https://example.com">x</a></p>`,
+		},
+		{
+			name:     "12 id and class pairs merge with the shorthand",
+			markdown: `Text {#first .lead id=second class="wide dark"}`,
+			expected: `<p id="second" class="lead wide dark">Text</p>`,
 		},
 		{
-			name:     "03 Two paragraphs",
-			markdown: "Paragraph One.\nParagraph Two.",
-			expected: "<p>Paragraph One.</p>\n<p>Paragraph Two.</p>\n",
+			name:     "13 Code language cannot be replaced",
+			markdown: "```go {data-language=js}\nx\n```",
+			expected: "<div class=\"code\" data-language=\"go\">\n<pre><code>x</code></pre>\n</div>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, convertSingleLine(tt.markdown), tt.expected)
+		})
+	}
+}
+
+func TestCustomHeadingIDIsReserved(t *testing.T) {
+	markdown := "## Setup {#usage}\n## Usage"
+
+	td.Cmp(t, GenerateHtmlBody(markdown), "<h2 id=\"usage\">Setup</h2>\n<h2 id=\"usage-2\">Usage</h2>\n")
+}
+
+func TestHeadingIDsDoNotCollide(t *testing.T) {
+	tests := []simpleTestCase{
+		{
+			name:     "01 Custom id declared after the generated slug",
+			markdown: "# Intro\n# Other {#intro}",
+			expected: "<h1 id=\"intro-2\">Intro</h1>\n<h1 id=\"intro\">Other</h1>\n",
 		},
 		{
-			name:     "04 Only whitespace",
-			markdown: "   \n\n   ",
-			expected: "\n",
+			name:     "02 Duplicate custom ids",
+			markdown: "# One {#same}\n# Two {#same}",
+			expected: "<h1 id=\"same\">One</h1>\n<h1 id=\"same-2\">Two</h1>\n",
 		},
 		{
-			name:     "05 Only newlines",
-			markdown: "\n\n\n",
-			expected: "\n",
+			name:     "03 Heading slug equal to a footnote id",
+			markdown: "# Fn 1\n# Fnref 1",
+			expected: "<h1 id=\"section-fn-1\">Fn 1</h1>\n<h1 id=\"section-fnref-1\">Fnref 1</h1>\n",
 		},
 	}
 
@@ -78,270 +1608,368 @@ func TestSimpleConversion(t *testing.T) {
 }
 
 // ---------------------------------------------------------------------------
-// Headers
+// Html Escaping
 // ---------------------------------------------------------------------------
 
-func TestHeaderConversion(t *testing.T) {
+func TestEscapeHTML(t *testing.T) {
 	tests := []simpleTestCase{
 		{
-			name:     "01 H1 header",
-			markdown: "# Main Title",
-			expected: "<h1>Main Title</h1>",
+			name:     "Ampersand escape",
+			markdown: "Tom & Jerry",
+			expected: "<p>Tom &amp; Jerry</p>",
 		},
 		{
-			name:     "02 H2 header",
-			markdown: "## Subtitle",
-			expected: "<h2>Subtitle</h2>",
+			name:     "Less than escape",
+			markdown: "5 < 10",
+			expected: "<p>5 &lt; 10</p>",
 		},
 		{
-			name:     "03 H3 header",
-			markdown: "### Sub Subtitle",
-			expected: "<h3>Sub Subtitle</h3>",
+			name:     "Greater than escape",
+			markdown: "10 > 5",
+			expected: "<p>10 &gt; 5</p>",
 		},
 		{
-			name:     "04 H1 with inline code",
-			markdown: "# Title with `code`",
-			expected: "<h1>Title with <code>code</code></h1>",
+			name:     "Double quote escape",
+			markdown: `Say "hello"`,
+			expected: "<p>Say &quot;hello&quot;</p>",
 		},
 		{
-			name:     "05 H2 with link",
-			markdown: "## See [docs](https://example.com)",
-			expected: "<h2>See <a href=\"https://example.com\">docs</a></h2>",
+			name:     "Single quote escape",
+			markdown: "It's great",
+			expected: "<p>It&#39;s great</p>",
 		},
 		{
-			name:     "06 Bold text in header",
-			markdown: "#### Title with **bold** text",
-			expected: "<h4>Title with <strong>bold</strong> text</h4>",
+			name:     "Less than not starting a tag",
+			markdown: `a <3 b and <not a tag`,
+			expected: `<p>a &lt;3 b and &lt;not a tag</p>`,
 		},
 		{
-			name:     "07 Italic text in paragraph",
-			markdown: "#### Title with *italic* text",
-			expected: "<h4>Title with <em>italic</em> text</h4>",
+			name:     "Multiple special chars",
+			markdown: `if a < b && c > "d"`,
+			expected: `<p>if a &lt; b &amp;&amp; c &gt; &quot;d&quot;</p>`,
+		},
+		{
+			name:     "Empty markup",
+			markdown: "",
+			expected: "",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			td.Cmp(t, processSingleLine(tt.markdown), tt.expected)
+			td.Cmp(t, convertSingleLine(tt.markdown), tt.expected)
 		})
 	}
 }
 
-// ---------------------------------------------------------------------------
-// Block Quote conversions
-// ---------------------------------------------------------------------------
-
-func TestBlockQuoteConversion(t *testing.T) {
+func TestEntityAndEscapeConversion(t *testing.T) {
 	tests := []simpleTestCase{
 		{
-			name:     "01 Basic Block Quote",
-			markdown: "> Lorem impsum dolor sit amet.",
-			expected: "<blockquote>Lorem impsum dolor sit amet.</blockquote>\n",
+			name:     "01 Named entities pass through",
+			markdown: "&copy; 2026 &bdquo;cytat&rdquo; a&nbsp;b",
+			expected: "<p>&copy; 2026 &bdquo;cytat&rdquo; a&nbsp;b</p>",
 		},
 		{
-			name:     "02 Block Quote with Callout",
-			markdown: "> Rule of thumb: Lorem impsum dolor sit amet.",
-			expected: "<blockquote><strong>Rule of thumb:</strong> Lorem impsum dolor sit amet.</blockquote>\n",
+			name:     "02 Numeric entities pass through",
+			markdown: "&#8222; &#x201E;",
+			expected: "<p>&#8222; &#x201E;</p>",
 		},
 		{
-			name:     "03 Callout ignored after comma",
-			markdown: "> Rule of thumb, actually: Lorem impsum dolor sit amet.",
-			expected: "<blockquote>Rule of thumb, actually: Lorem impsum dolor sit amet.</blockquote>\n",
+			name:     "03 Unknown entity is escaped",
+			markdown: "&foo; and AT&T",
+			expected: "<p>&amp;foo; and AT&amp;T</p>",
 		},
 		{
-			name:     "04 Callout ignored after period",
-			markdown: "> Rule of thumb. Actually: Lorem impsum dolor sit amet.",
-			expected: "<blockquote>Rule of thumb. Actually: Lorem impsum dolor sit amet.</blockquote>\n",
+			name:     "04 Entities inside code spans are escaped",
+			markdown: "`&copy;`",
+			expected: "<p><code>&amp;copy;</code></p>",
 		},
 		{
-			name:     "05 Callout ignored after semicolon",
-			markdown: "> Rule of thumb; actually: Lorem impsum dolor sit amet.",
-			expected: "<blockquote>Rule of thumb; actually: Lorem impsum dolor sit amet.</blockquote>\n",
+			name:     "05 Escaped entity",
+			markdown: "\\&copy;",
+			expected: "<p>&amp;copy;</p>",
 		},
 		{
-			name:     "06 Callout ignored after hyphen",
-			markdown: "> Rule of thumb - actually: Lorem impsum dolor sit amet.",
-			expected: "<blockquote>Rule of thumb - actually: Lorem impsum dolor sit amet.</blockquote>\n",
+			name:     "06 Entity in link destination is not escaped twice",
+			markdown: "[a](https://x.com/?a=1&amp;b=2)",
+			expected: `<p><a href="https://x.com/?a=1&amp;b=2">a</a></p>`,
 		},
 		{
-			name:     "07 Block Quote preserves inline formatting",
-			markdown: "> Use **bold**, *italic*, `code`, and [link](https://example.com)",
-			expected: "<blockquote>Use <strong>bold</strong>, <em>italic</em>, <code>code</code>, and <a href=\"https://example.com\">link</a></blockquote>\n",
+			name:     "07 Escaped parenthesis in link destination",
+			markdown: "[a](x\\)y)",
+			expected: `<p><a href="x)y">a</a></p>`,
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			td.Cmp(t, GenerateHtmlBody(tt.markdown), tt.expected)
-		})
-	}
-}
-
-// ---------------------------------------------------------------------------
-// Inline code
-// ---------------------------------------------------------------------------
-
-func TestInlineCodeConversion(t *testing.T) {
-	tests := []simpleTestCase{
 		{
-			name:     "Inline_code_simple",
-			markdown: "`function()`",
-			expected: "<p><code>function()</code></p>",
+			name:     "08 Escaped quote in link title",
+			markdown: `[a](url "say \"hi\"")`,
+			expected: `<p><a href="url" title="say &quot;hi&quot;">a</a></p>`,
 		},
 		{
-			name:     "Inline_code_Multiple_elements",
-			markdown: "Call function `foo()` and `bar()`",
-			expected: "<p>Call function <code>foo()</code> and <code>bar()</code></p>",
+			name:     "09 All ASCII punctuation can be escaped",
+			markdown: "\\!\\\"\\#\\$\\%\\&\\'\\(\\)\\*\\+\\,\\-\\.\\/\\:\\;\\<\\=\\>\\?\\@\\[\\\\\\]\\^\\_\\`\\{\\|\\}\\~",
+			expected: "<p>!&quot;#$%&amp;&#39;()*+,-./:;&lt;=&gt;?@[\\]^_`{|}~</p>",
 		},
 		{
-			name:     "Inline_code_with_special_chars",
-			markdown: "Use `<tag>`",
-			expected: "<p>Use <code>&lt;tag&gt;</code></p>",
+			name:     "10 Backslash before other characters stays",
+			markdown: "C:\\Users\\a",
+			expected: "<p>C:\\Users\\a</p>",
 		},
 		{
-			name:     "Inline_code_at_start",
-			markdown: "`code` at start",
-			expected: "<p><code>code</code> at start</p>",
+			name:     "11 Escaped block markers",
+			markdown: "\\# not heading",
+			expected: "<p># not heading</p>",
 		},
 		{
-			name:     "Inline_code_at_end",
-			markdown: "at end `code`",
-			expected: "<p>at end <code>code</code></p>",
+			name:     "12 Entity in heading",
+			markdown: "## Rock &amp; Roll",
+			expected: `<h2 id="rock-roll">Rock &amp; Roll</h2>`,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			td.Cmp(t, processSingleLine(tt.markdown), tt.expected)
+			td.Cmp(t, convertSingleLine(tt.markdown), tt.expected)
 		})
 	}
 }
 
 // ---------------------------------------------------------------------------
-// Hyperlinks
+// Raw HTML
 // ---------------------------------------------------------------------------
 
-func TestLinkConversion(t *testing.T) {
-	tests := []simpleTestCase{
+func TestRawHTMLConversion(t *testing.T) {
+	tests := []multilineTestCase{
 		{
-			name:     "Markdown link",
-			markdown: "[GitHub](https://github.com)",
-			expected: `<p><a href="https://github.com">GitHub</a></p>`,
+			name:     "01 Block tag is passed through until a blank line",
+			markdown: []string{"<div class=\"note\">", "*not emphasis*", "</div>", "", "After"},
+			expected: []string{"<div class=\"note\">", "*not emphasis*", "</div>", "", "<p>After</p>", ""},
 		},
 		{
-			name:     "Auto-detected HTTPS URL",
-			markdown: "Visit https://example.com to get started",
-			expected: `<p>Visit <a href="https://example.com">https://example.com</a> to get started</p>`,
+			name:     "02 Details block with markdown after a blank line",
+			markdown: []string{"<details>", "<summary>More</summary>", "", "**Hidden** text", "", "</details>"},
+			expected: []string{"<details>", "<summary>More</summary>", "", "<p><strong>Hidden</strong> text</p>", "", "</details>", ""},
 		},
 		{
-			name:     "Auto-detected HTTP URL",
-			markdown: "Go to http://example.com",
-			expected: `<p>Go to <a href="http://example.com">http://example.com</a></p>`,
+			name:     "03 Multi-line comment",
+			markdown: []string{"<!-- draft", "", "notes -->", "Text"},
+			expected: []string{"<!-- draft", "", "notes -->", "<p>Text</p>", ""},
 		},
 		{
-			name:     "Multiple links",
-			markdown: "[Google](https://google.com) and [Bing](https://bing.com)",
-			expected: `<p><a href="https://google.com">Google</a> and <a href="https://bing.com">Bing</a></p>`,
+			name:     "04 Script block keeps blank lines",
+			markdown: []string{"<script>", "let a = 1 < 2;", "", "</script>"},
+			expected: []string{"<script>", "let a = 1 < 2;", "", "</script>", ""},
 		},
 		{
-			name:     "Link with special chars in URL",
-			markdown: "[Docs](https://example.com/docs?id=123&format=html)",
-			expected: `<p><a href="https://example.com/docs?id=123&amp;format=html">Docs</a></p>`,
+			name:     "05 Block tag interrupts a paragraph",
+			markdown: []string{"Text", "<iframe src=\"https://example.com\"></iframe>"},
+			expected: []string{"<p>Text</p>", "<iframe src=\"https://example.com\"></iframe>", ""},
+		},
+		{
+			name:     "06 Inline tag does not interrupt a paragraph",
+			markdown: []string{"Text", "<span>more</span>"},
+			expected: []string{"<p>Text", "<span>more</span></p>", ""},
+		},
+		{
+			name:     "07 Standalone inline tag forms a block",
+			markdown: []string{"<a href=\"/x\">", "*link*", "</a>"},
+			expected: []string{"<a href=\"/x\">", "*link*", "</a>", ""},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			td.Cmp(t, processSingleLine(tt.markdown), tt.expected)
+			expected, markdown := tt.toString("")
+			td.Cmp(t, GenerateHtmlBody(markdown), expected)
 		})
 	}
 }
 
-// ---------------------------------------------------------------------------
-// Images
-// ---------------------------------------------------------------------------
-
-func TestMarkdownImageConversion(t *testing.T) {
+func TestInlineHTMLConversion(t *testing.T) {
 	tests := []simpleTestCase{
 		{
-			name:     "01 Standard markdown image renders as img tag without paragraph",
-			markdown: "![Tux, the Linux mascot](/assets/images/tux.png)",
-			expected: "<img src=\"/assets/images/tux.png\" alt=\"Tux, the Linux mascot\">\n",
+			name:     "01 Tag with attributes",
+			markdown: `A <span class="tag" data-x='1'>*tagged*</span> word`,
+			expected: `<p>A <span class="tag" data-x='1'><em>tagged</em></span> word</p>`,
 		},
 		{
-			name:     "02 Figure-prefixed alt text renders figure with figcaption",
-			markdown: "![figure: Linux mascot known as tux](/imgs/tux.png)",
-			expected: "<figure>\n  <img src=\"/imgs/tux.png\" alt=\"Linux mascot known as tux\">\n  <figcaption>Linux mascot known as tux</figcaption>\n</figure>\n",
+			name:     "02 Self-closing tag and comment",
+			markdown: "Line<br/> <!-- note --> end",
+			expected: "<p>Line<br/> <!-- note --> end</p>",
 		},
 		{
-			name:     "03 Raw html image passes through unchanged",
-			markdown: `<img src="/assets/images/tux.png" alt="Tux, the Linux mascot">`,
-			expected: `<img src="/assets/images/tux.png" alt="Tux, the Linux mascot">` + "\n",
+			name:     "03 Tag inside code span stays code",
+			markdown: "`<b>`",
+			expected: "<p><code>&lt;b&gt;</code></p>",
+		},
+		{
+			name:     "04 Invalid tag is escaped",
+			markdown: "<a href=\"x> and <1>",
+			expected: "<p>&lt;a href=&quot;x&gt; and &lt;1&gt;</p>",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			td.Cmp(t, GenerateHtmlBody(tt.markdown), tt.expected)
+			td.Cmp(t, convertSingleLine(tt.markdown), tt.expected)
 		})
 	}
 }
 
-// ---------------------------------------------------------------------------
-// Html Escaping
-// ---------------------------------------------------------------------------
+func TestSafeHTMLSanitizer(t *testing.T) {
+	options := DefaultConvertOptions()
+	options.SafeHTML = true
 
-func TestEscapeHTML(t *testing.T) {
-	tests := []simpleTestCase{
+	tests := []struct {
+		name        string
+		markdown    string
+		expected    string
+		diagnostics []string
+	}{
 		{
-			name:     "Ampersand escape",
-			markdown: "Tom & Jerry",
-			expected: "<p>Tom &amp; Jerry</p>",
+			name:        "01 Script tag injection",
+			markdown:    `<script>alert("XSS")</script>`,
+			expected:    "",
+			diagnostics: []string{"removed <script>: tag is not allowed"},
 		},
 		{
-			name:     "Less than escape",
-			markdown: "5 < 10",
-			expected: "<p>5 &lt; 10</p>",
+			name:        "02 Disallowed tag is removed with its content",
+			markdown:    "<div>\n<iframe src=\"https://evil.example\">frame</iframe>\n</div>\n\nText",
+			expected:    "<div>\n\n</div>\n\n<p>Text</p>\n",
+			diagnostics: []string{"removed <iframe>: tag is not allowed"},
 		},
 		{
-			name:     "Greater than escape",
-			markdown: "10 > 5",
-			expected: "<p>10 &gt; 5</p>",
+			name:     "03 Event handlers and javascript URLs in inline HTML",
+			markdown: `Click <a href="javascript:alert(1)" onclick="x()" title="t">**here**</a>`,
+			expected: `<p>Click <a title="t"><strong>here</strong></a></p>` + "\n",
+			diagnostics: []string{
+				`removed href attribute from <a>: URL scheme "javascript" is not allowed`,
+				"removed onclick attribute from <a>: attribute is not allowed",
+			},
 		},
 		{
-			name:     "Double quote escape",
-			markdown: `Say "hello"`,
-			expected: "<p>Say &quot;hello&quot;</p>",
+			name:        "04 Image with onerror",
+			markdown:    `<img src="x.png" onerror="alert(1)">`,
+			expected:    `<img src="x.png">` + "\n",
+			diagnostics: []string{"removed onerror attribute from <img>: attribute is not allowed"},
 		},
 		{
-			name:     "Single quote escape",
-			markdown: "It's great",
-			expected: "<p>It&#39;s great</p>",
+			name:        "05 Javascript URL in a markdown link",
+			markdown:    "[x](javascript:alert%281%29) [y](JaVaScRiPt&#58;void)",
+			expected:    "<p><a>x</a> <a>y</a></p>\n",
+			diagnostics: []string{`removed href attribute from <a>: URL scheme "javascript" is not allowed`, `removed href attribute from <a>: URL scheme "javascript" is not allowed`},
 		},
 		{
-			name:     "Script tag injection",
-			markdown: `<script>alert("XSS")</script>`,
-			expected: `<p>&lt;script&gt;alert(&quot;XSS&quot;)&lt;/script&gt;</p>`,
+			name:     "06 Allowed and relative URLs are kept",
+			markdown: "[a](https://example.com) [b](mailto:a@b.pl) [c](/docs/) [d](#top) ![e](img/e.png)",
+			expected: `<p><a href="https://example.com">a</a> <a href="mailto:a@b.pl">b</a> <a href="/docs/">c</a> <a href="#top">d</a> <img src="img/e.png" alt="e"></p>` + "\n",
 		},
 		{
-			name:     "Multiple special chars",
-			markdown: `<script>alert("test & run")</script>`,
-			expected: `<p>&lt;script&gt;alert(&quot;test &amp; run&quot;)&lt;/script&gt;</p>`,
+			name:        "07 Attribute block with an event handler",
+			markdown:    "![tux](tux.png){.logo width=300 onclick=\"alert(1)\"}",
+			expected:    `<img src="tux.png" alt="tux" class="logo" width="300">` + "\n",
+			diagnostics: []string{"removed onclick attribute from <img>: attribute is not allowed"},
 		},
 		{
-			name:     "Empty markup",
-			markdown: "",
-			expected: "",
+			name:        "08 Comments are removed",
+			markdown:    "Text <!-- hidden -->",
+			expected:    "<p>Text </p>\n",
+			diagnostics: []string{"removed comment: only tags are allowed"},
+		},
+		{
+			name:     "09 Markdown output is not affected",
+			markdown: "# Title\n\n- [x] done\n\nNote[^1]\n\n[^1]: Footnote",
+			expected: "<h1 id=\"title\">Title</h1>\n\n<ul class=\"contains-task-list\">\n    <li class=\"task-list-item\"><input type=\"checkbox\" disabled checked> done</li>\n</ul>\n" +
+				"<p>Note<sup class=\"footnote-ref\"><a href=\"#fn-1\" id=\"fnref-1\">1</a></sup></p>\n\n" +
+				"<section class=\"footnotes\">\n<ol>\n    <li id=\"fn-1\">\n<p>Footnote <a href=\"#fnref-1\" class=\"footnote-backref\" aria-label=\"Back to reference 1\">↩</a></p>\n    </li>\n</ol>\n</section>\n",
+		},
+		{
+			name:        "10 Inline script is removed with its content",
+			markdown:    "text <script>alert(1)</script> after",
+			expected:    "<p>text  after</p>\n",
+			diagnostics: []string{"removed <script>: tag is not allowed"},
+		},
+		{
+			name:        "11 Inline style with markdown inside",
+			markdown:    "Styled <style>p{color:red} *a*</style> **text**",
+			expected:    "<p>Styled  <strong>text</strong></p>\n",
+			diagnostics: []string{"removed <style>: tag is not allowed"},
+		},
+		{
+			name:        "12 Unclosed inline script drops the rest of its paragraph only",
+			markdown:    "text <script>alert(1)\n\nNext",
+			expected:    "<p>text </p>\n\n<p>Next</p>\n",
+			diagnostics: []string{"removed <script>: tag is not allowed"},
+		},
+		{
+			name:        "13 Javascript URL in an autolink",
+			markdown:    "<javascript:alert(1)>",
+			expected:    "<p><a>javascript:alert(1)</a></p>\n",
+			diagnostics: []string{`removed href attribute from <a>: URL scheme "javascript" is not allowed`},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			td.Cmp(t, processSingleLine(tt.markdown), tt.expected)
+			result, err := ConvertMarkdownDocument(tt.markdown, "{{ .Content }}", "", options)
+			td.CmpNoError(t, err)
+			td.Cmp(t, result.HTML, tt.expected)
+
+			var diagnostics []string
+			for _, diagnostic := range result.Diagnostics {
+				diagnostics = append(diagnostics, diagnostic.String())
+			}
+			td.Cmp(t, diagnostics, tt.diagnostics)
 		})
 	}
 }
 
+func TestSanitizerPolicyFile(t *testing.T) {
+	policy, err := ParseSanitizerPolicy([]byte(`{
+		"tags": {"a": ["href", "rel"], "p": []},
+		"globalAttributes": [],
+		"urlSchemes": ["https"]
+	}`))
+	td.CmpNoError(t, err)
+
+	options := DefaultConvertOptions()
+	options.SafeHTML = true
+	options.HTMLPolicy = policy
+	markdown := `<a href="http://a.pl" rel="nofollow" class="x">a</a> <b>b</b> [c](https://c.pl){.link}`
+
+	result, err := ConvertMarkdownDocument(markdown, "{{ .Content }}", "", options)
+	td.CmpNoError(t, err)
+	td.Cmp(t, result.HTML, `<p><a rel="nofollow">a</a> b <a href="https://c.pl">c</a></p>`+"\n")
+	td.Cmp(t, len(result.Diagnostics), 4)
+}
+
+func TestSanitizerPolicyKeepsGeneratedIDs(t *testing.T) {
+	policy, err := ParseSanitizerPolicy([]byte(`{"tags": {"h1": [], "h2": ["class"], "p": [], "sup": [], "a": ["href"]}}`))
+	td.CmpNoError(t, err)
+
+	options := DefaultConvertOptions()
+	options.SafeHTML = true
+	options.HTMLPolicy = policy
+	markdown := "# One\n\n## Two {#custom .x}\n\nText[^1]\n\n[^1]: Note"
+
+	result, err := ConvertMarkdownDocument(markdown, "{{ .TOC }}{{ .Content }}", "", options)
+	td.CmpNoError(t, err)
+	td.CmpContains(t, result.HTML, `<a href="#one">One</a>`)
+	td.CmpContains(t, result.HTML, `<a href="#two">Two</a>`)
+	td.CmpContains(t, result.HTML, `<h1 id="one">One</h1>`)
+	td.CmpContains(t, result.HTML, `<h2 id="two" class="x">Two</h2>`)
+	td.CmpContains(t, result.HTML, `<a href="#fn-1" id="fnref-1">1</a>`)
+	td.CmpContains(t, result.HTML, `<li id="fn-1">`)
+	td.Cmp(t, result.Diagnostics, []Diagnostic{{Element: "<h2>", Attribute: "id", Reason: "attribute is not allowed"}})
+}
+
+func TestSanitizerPolicyFileErrors(t *testing.T) {
+	_, err := ParseSanitizerPolicy([]byte(`{"tag": {"a": ["href"]}}`))
+	td.CmpString(t, err, `error parsing sanitizer policy: json: unknown field "tag"`)
+
+	_, err = ParseSanitizerPolicy([]byte(`{"tags": ["a"]}`))
+	td.CmpContains(t, err, "error parsing sanitizer policy")
+}
+
 // ---------------------------------------------------------------------------
 // Block - Ordered and Point Lists
 // ---------------------------------------------------------------------------
@@ -538,29 +2166,92 @@ func TestMixedListConversion(t *testing.T) {
 				"•••<li>Point Subitem 2.1</li>",
 				"••</ul>",
 				"•</li>",
-				"</ol>",
-				"",
-			},
+				"</ol>",
+				"",
+			},
+		},
+		{
+			name: "02. Point lists with ordered subitems",
+			markdown: []string{
+				"- Main",
+				"   1. Ordered One",
+				"   2. Ordered Two",
+				"- Another Main"},
+			expected: []string{
+				"<ul>",
+				"•<li>Main",
+				"••<ol>",
+				"•••<li>Ordered One</li>",
+				"•••<li>Ordered Two</li>",
+				"••</ol>",
+				"•</li>",
+				"•<li>Another Main</li>",
+				"</ul>",
+				"",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, markdown := tt.toString(indentHtmlWith4Spaces)
+			td.Cmp(t, GenerateHtmlBody(markdown), expected)
+		})
+	}
+}
+
+func TestTaskListConversion(t *testing.T) {
+	tests := []multilineTestCase{
+		{
+			name: "01 Checked and unchecked items",
+			markdown: []string{
+				"- [x] Write the parser",
+				"- [ ] Write the docs"},
+			expected: []string{
+				"<ul class=\"contains-task-list\">",
+				"•<li class=\"task-list-item\"><input type=\"checkbox\" disabled checked> Write the parser</li>",
+				"•<li class=\"task-list-item\"><input type=\"checkbox\" disabled> Write the docs</li>",
+				"</ul>",
+				""},
+		},
+		{
+			name: "02 Task items mixed with regular items",
+			markdown: []string{
+				"- [X] Done",
+				"- Plain item",
+				"- [link](https://example.com)"},
+			expected: []string{
+				"<ul class=\"contains-task-list\">",
+				"•<li class=\"task-list-item\"><input type=\"checkbox\" disabled checked> Done</li>",
+				"•<li>Plain item</li>",
+				"•<li><a href=\"https://example.com\">link</a></li>",
+				"</ul>",
+				""},
+		},
+		{
+			name: "03 Nested task list",
+			markdown: []string{
+				"- Release",
+				"    - [ ] Tag version"},
+			expected: []string{
+				"<ul>",
+				"•<li>Release",
+				"••<ul class=\"contains-task-list\">",
+				"•••<li class=\"task-list-item\"><input type=\"checkbox\" disabled> Tag version</li>",
+				"••</ul>",
+				"•</li>",
+				"</ul>",
+				""},
 		},
 		{
-			name: "02. Point lists with ordered subitems",
+			name: "04 Brackets without a space are plain text",
 			markdown: []string{
-				"- Main",
-				"   1. Ordered One",
-				"   2. Ordered Two",
-				"- Another Main"},
+				"- [x]done"},
 			expected: []string{
 				"<ul>",
-				"•<li>Main",
-				"••<ol>",
-				"•••<li>Ordered One</li>",
-				"•••<li>Ordered Two</li>",
-				"••</ol>",
-				"•</li>",
-				"•<li>Another Main</li>",
+				"•<li>[x]done</li>",
 				"</ul>",
-				"",
-			},
+				""},
 		},
 	}
 
@@ -572,6 +2263,15 @@ func TestMixedListConversion(t *testing.T) {
 	}
 }
 
+func TestTaskListCounts(t *testing.T) {
+	markdown := "- [x] One\n- [ ] Two\n    - [x] Three\n- Four"
+
+	result, err := ConvertMarkdownToHTML(markdown, "{{ .TasksDone }}/{{ .TasksTotal }} done", "")
+
+	td.Cmp(t, err, nil)
+	td.Cmp(t, result, "2/3 done")
+}
+
 // ---------------------------------------------------------------------------
 // Block - Code
 // ---------------------------------------------------------------------------
@@ -697,6 +2397,155 @@ func TestCodeBlockConversion(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// Block - Tables
+// ---------------------------------------------------------------------------
+
+func TestTableConversion(t *testing.T) {
+	tests := []multilineTestCase{
+		{
+			name: "01 Header and body rows",
+			markdown: []string{
+				"| API | Method |",
+				"| --- | ------ |",
+				"| Users | GET |",
+				"| Orders | POST |"},
+			expected: []string{
+				"<table>",
+				"•<thead>",
+				"••<tr>",
+				"•••<th>API</th>",
+				"•••<th>Method</th>",
+				"••</tr>",
+				"•</thead>",
+				"•<tbody>",
+				"••<tr>",
+				"•••<td>Users</td>",
+				"•••<td>GET</td>",
+				"••</tr>",
+				"••<tr>",
+				"•••<td>Orders</td>",
+				"•••<td>POST</td>",
+				"••</tr>",
+				"•</tbody>",
+				"</table>",
+				""},
+		},
+		{
+			name: "02 Column alignment becomes a class",
+			markdown: []string{
+				"Left | Center | Right",
+				":--- | :----: | ----:",
+				"a | b | c"},
+			expected: []string{
+				"<table>",
+				"•<thead>",
+				"••<tr>",
+				"•••<th class=\"align-left\">Left</th>",
+				"•••<th class=\"align-center\">Center</th>",
+				"•••<th class=\"align-right\">Right</th>",
+				"••</tr>",
+				"•</thead>",
+				"•<tbody>",
+				"••<tr>",
+				"•••<td class=\"align-left\">a</td>",
+				"•••<td class=\"align-center\">b</td>",
+				"•••<td class=\"align-right\">c</td>",
+				"••</tr>",
+				"•</tbody>",
+				"</table>",
+				""},
+		},
+		{
+			name: "03 Inline formatting and escaped pipes in cells",
+			markdown: []string{
+				"| Syntax | Result |",
+				"|---|---|",
+				"| `a \\| b` | **bold** or [link](https://example.com) |"},
+			expected: []string{
+				"<table>",
+				"•<thead>",
+				"••<tr>",
+				"•••<th>Syntax</th>",
+				"•••<th>Result</th>",
+				"••</tr>",
+				"•</thead>",
+				"•<tbody>",
+				"••<tr>",
+				"•••<td><code>a | b</code></td>",
+				"•••<td><strong>bold</strong> or <a href=\"https://example.com\">link</a></td>",
+				"••</tr>",
+				"•</tbody>",
+				"</table>",
+				""},
+		},
+		{
+			name: "04 Missing cells are filled and extra cells dropped",
+			markdown: []string{
+				"| A | B |",
+				"|---|---|",
+				"| 1 |",
+				"| 1 | 2 | 3 |"},
+			expected: []string{
+				"<table>",
+				"•<thead>",
+				"••<tr>",
+				"•••<th>A</th>",
+				"•••<th>B</th>",
+				"••</tr>",
+				"•</thead>",
+				"•<tbody>",
+				"••<tr>",
+				"•••<td>1</td>",
+				"•••<td></td>",
+				"••</tr>",
+				"••<tr>",
+				"•••<td>1</td>",
+				"•••<td>2</td>",
+				"••</tr>",
+				"•</tbody>",
+				"</table>",
+				""},
+		},
+		{
+			name: "05 Table ends at blank line, header only table has no body",
+			markdown: []string{
+				"| A |",
+				"|---|",
+				"",
+				"After table"},
+			expected: []string{
+				"<table>",
+				"•<thead>",
+				"••<tr>",
+				"•••<th>A</th>",
+				"••</tr>",
+				"•</thead>",
+				"</table>",
+				"",
+				"<p>After table</p>",
+				""},
+		},
+		{
+			name: "06 Pipe without delimiter row stays a paragraph",
+			markdown: []string{
+				"a | b",
+				"c | d"},
+			expected: []string{
+				"<p>a | b",
+				"c | d</p>",
+				""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, markdown := tt.toString(indentHtmlWith4Spaces)
+			td.Cmp(t, GenerateHtmlBody(markdown), expected)
+		})
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Block - Lists with Code
 // ---------------------------------------------------------------------------
@@ -839,7 +2688,7 @@ func TestYamlFrontmatterConversion(t *testing.T) {
 				"# Lorem impsum",
 			},
 			expected: []string{
-				"<h1>Lorem impsum</h1>",
+				"<h1 id=\"lorem-impsum\">Lorem impsum</h1>",
 				""},
 		},
 		{
@@ -856,29 +2705,28 @@ func TestYamlFrontmatterConversion(t *testing.T) {
 				""},
 		},
 		{
-			name: "03 Mid-document delimiter is preserved",
+			name: "03 Mid-document delimiter is a setext underline",
 			markdown: []string{
 				"Lorem impsum",
 				"---",
 				"Dolor sit amet",
 			},
 			expected: []string{
-				"<p>Lorem impsum</p>",
-				"<p>---</p>",
+				"<h2 id=\"lorem-impsum\">Lorem impsum</h2>",
 				"<p>Dolor sit amet</p>",
 				""},
 		},
 		{
-			name: "04 Unterminated leading delimiter is preserved",
+			name: "04 Unterminated leading delimiter is a thematic break",
 			markdown: []string{
 				"---",
 				"postId: \"class-helpers-intro\"",
 				"Impsum dolor",
 			},
 			expected: []string{
-				"<p>---</p>",
-				"<p>postId: &quot;class-helpers-intro&quot;</p>",
-				"<p>Impsum dolor</p>",
+				"<hr>",
+				"<p>postId: &quot;class-helpers-intro&quot;",
+				"Impsum dolor</p>",
 				""},
 		},
 	}
@@ -891,6 +2739,488 @@ func TestYamlFrontmatterConversion(t *testing.T) {
 	}
 }
 
+func TestParseYAMLFrontMatter(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		expected map[string]any
+	}{
+		{
+			name:     "01 Scalars keep their types",
+			lines:    []string{"title: Post", "draft: false", "weight: 3", "ratio: 0.5", "empty:", "none: ~"},
+			expected: map[string]any{"title": "Post", "draft": false, "weight": 3, "ratio": 0.5, "empty": nil, "none": nil},
+		},
+		{
+			name:     "02 Quoted strings with colons and escapes",
+			lines:    []string{`title: "Go: the good parts"`, `quote: 'It''s 10:30'`, `escaped: "a\"b\tc \u0105"`, `number: "42"`},
+			expected: map[string]any{"title": "Go: the good parts", "quote": "It's 10:30", "escaped": "a\"b\tc ą", "number": "42"},
+		},
+		{
+			name:     "03 Comments",
+			lines:    []string{"# leading comment", "title: Post # trailing comment", "url: http://example.com/#anchor", ""},
+			expected: map[string]any{"title": "Post", "url": "http://example.com/#anchor"},
+		},
+		{
+			name:  "04 Block and flow sequences",
+			lines: []string{"tags:", "  - go", "  - markdown", "aliases:", "- /old", "- /older", "ids: [1, 'two', [3]]", "none: []"},
+			expected: map[string]any{
+				"tags":    []any{"go", "markdown"},
+				"aliases": []any{"/old", "/older"},
+				"ids":     []any{1, "two", []any{3}},
+				"none":    []any{},
+			},
+		},
+		{
+			name:  "05 Nested maps and lists of maps",
+			lines: []string{"author:", "  name: Bogdan", "  social:", "    github: bogdanpolak", "links:", "  - title: Home", "    url: /", "  - title: Blog", "    url: /blog", "flow: {a: 1, b: [x]}"},
+			expected: map[string]any{
+				"author": map[string]any{"name": "Bogdan", "social": map[string]any{"github": "bogdanpolak"}},
+				"links":  []any{map[string]any{"title": "Home", "url": "/"}, map[string]any{"title": "Blog", "url": "/blog"}},
+				"flow":   map[string]any{"a": 1, "b": []any{"x"}},
+			},
+		},
+		{
+			name:  "06 Literal and folded block scalars",
+			lines: []string{"code: |", "  line 1", "    indented", "", "summary: >", "  folded", "  text", "", "  new paragraph", "strip: |-", "  no newline", "after: x"},
+			expected: map[string]any{
+				"code":    "line 1\n  indented\n",
+				"summary": "folded text\nnew paragraph\n",
+				"strip":   "no newline",
+				"after":   "x",
+			},
+		},
+		{
+			name:     "07 Empty front matter",
+			lines:    []string{"", "# only a comment"},
+			expected: map[string]any{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta, err := parseYAMLFrontMatter(tt.lines, 2)
+			td.CmpNoError(t, err)
+			td.Cmp(t, meta, tt.expected)
+		})
+	}
+}
+
+func TestParseYAMLFrontMatterErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		expected string
+	}{
+		{name: "01 Missing colon", lines: []string{"title: Post", "just text"}, expected: `line 3: expected "key: value", got "just text"`},
+		{name: "02 Unterminated quote", lines: []string{`title: "Post`}, expected: "line 2: unterminated quoted string"},
+		{name: "03 Unexpected indentation", lines: []string{"title: Post", "  author: Me"}, expected: "line 3: unexpected indentation"},
+		{name: "04 Duplicate key", lines: []string{"a: 1", "b: 2", "a: 3"}, expected: `line 4: duplicate key "a"`},
+		{name: "05 Unterminated flow sequence", lines: []string{"tags: [a, b"}, expected: `line 2: unterminated flow collection, ']' expected`},
+		{name: "06 Text after a quoted value", lines: []string{`title: "a" b`}, expected: `line 2: unexpected "b" after the value`},
+		{name: "07 Top level list", lines: []string{"- a"}, expected: "line 2: front matter must be a mapping of keys to values"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseYAMLFrontMatter(tt.lines, 2)
+			td.CmpString(t, err, tt.expected)
+		})
+	}
+}
+
+func TestParseTOMLFrontMatter(t *testing.T) {
+	lines := []string{
+		`title = "Go: the good parts" # comment`,
+		`path = 'C:\docs'`,
+		"date = 2026-03-13",
+		"weight = 1_000",
+		"ratio = 0.5",
+		"draft = false",
+		"tags = [",
+		`  "go",  # first`,
+		`  "markdown",`,
+		"]",
+		"author.name = \"Bogdan\"",
+		`intro = """`,
+		`Multi-line \`,
+		`  text"""`,
+		"",
+		"[params]",
+		"color = { light = \"white\", dark = \"black\" }",
+		"",
+		"[[links]]",
+		"url = \"/\"",
+		"[[links]]",
+		"url = \"/blog\"",
+	}
+
+	meta, err := parseTOMLFrontMatter(lines, 2)
+
+	td.CmpNoError(t, err)
+	td.Cmp(t, meta, map[string]any{
+		"title":  "Go: the good parts",
+		"path":   `C:\docs`,
+		"date":   "2026-03-13",
+		"weight": 1000,
+		"ratio":  0.5,
+		"draft":  false,
+		"tags":   []any{"go", "markdown"},
+		"author": map[string]any{"name": "Bogdan"},
+		"intro":  "Multi-line text",
+		"params": map[string]any{"color": map[string]any{"light": "white", "dark": "black"}},
+		"links":  []any{map[string]any{"url": "/"}, map[string]any{"url": "/blog"}},
+	})
+}
+
+func TestParseTOMLFrontMatterErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		expected string
+	}{
+		{name: "01 Missing equals sign", lines: []string{`title = "a"`, "author Bogdan"}, expected: `line 3: expected "key = value", got "Bogdan"`},
+		{name: "02 Bare string value", lines: []string{"title = Post"}, expected: `line 2: invalid value "Post"`},
+		{name: "03 Duplicate key", lines: []string{"a = 1", "a = 2"}, expected: `line 3: duplicate key "a"`},
+		{name: "04 Unterminated array", lines: []string{"a = 1", "tags = [", `  "go",`}, expected: `line 3: array is not closed with "]"`},
+		{name: "05 Duplicate table", lines: []string{"[a]", "[a]"}, expected: `line 3: table "a" is already defined`},
+		{name: "06 Unterminated string", lines: []string{`title = "Post`}, expected: "line 2: unterminated string"},
+		{name: "07 Text after a string", lines: []string{`title = "Post" draft`}, expected: `line 2: unexpected "draft" at the end of the line`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTOMLFrontMatter(tt.lines, 2)
+			td.CmpString(t, err, tt.expected)
+		})
+	}
+}
+
+func TestFrontMatterFormats(t *testing.T) {
+	template := `{{ .Title }}|{{ .Date }}|{{ .TocLevels }}|{{ range .Meta.tags }}#{{ . }}{{ end }}|{{ .Content }}`
+	tests := []struct {
+		name     string
+		markdown string
+	}{
+		{
+			name:     "01 YAML",
+			markdown: "---\ntitle: Post\ndate: 2026-03-13\ntocLevels: 2\ntags: [a, b]\n---\nText",
+		},
+		{
+			name:     "02 TOML",
+			markdown: "+++\ntitle = \"Post\"\ndate = 2026-03-13\ntocLevels = 2\ntags = [\"a\", \"b\"]\n+++\nText",
+		},
+		{
+			name:     "03 JSON",
+			markdown: "{\n  \"title\": \"Post\",\n  \"date\": \"2026-03-13\",\n  \"tocLevels\": 2,\n  \"tags\": [\"a\", \"b\"]\n}\nText",
+		},
+		{
+			name:     "04 JSON on one line",
+			markdown: "{\"title\": \"Post\", \"date\": \"2026-03-13\", \"tocLevels\": 2, \"tags\": [\"a\", \"b\"]}\nText",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertMarkdownToHTML(tt.markdown, template, "")
+			td.CmpNoError(t, err)
+			td.Cmp(t, result, "Post|2026-03-13|2|#a#b|<p>Text</p>\n")
+		})
+	}
+}
+
+func TestFrontMatterFormatErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		expected string
+	}{
+		{
+			name:     "01 Unclosed TOML block",
+			markdown: "+++\ntitle = \"Post\"\n\nText",
+			expected: `error parsing front matter: line 1: front matter is not closed with "+++"`,
+		},
+		{
+			name:     "02 Malformed TOML",
+			markdown: "+++\ntitle = \"Post\"\ndate = 13.03.2026\n+++\nText",
+			expected: `error parsing front matter: line 3: invalid value "13.03.2026"`,
+		},
+		{
+			name:     "03 Malformed JSON",
+			markdown: "{\n  \"title\": \"Post\"\n  \"date\": \"2026-03-13\"\n}\nText",
+			expected: "error parsing front matter: line 3: invalid character '\"' after object key:value pair",
+		},
+		{
+			name:     "04 Unclosed JSON",
+			markdown: "{\n  \"title\": \"Post\",\n  \"tags\": [\"a\"",
+			expected: "error parsing front matter: line 1: JSON object is not closed",
+		},
+		{
+			name:     "05 Markdown inside JSON",
+			markdown: "{\n  \"title\": \"Post\",\n\nText",
+			expected: "error parsing front matter: line 4: invalid character 'T' looking for beginning of object key string",
+		},
+		{
+			name:     "06 Text after JSON",
+			markdown: "{\"title\": \"Post\"} Text",
+			expected: `error parsing front matter: line 1: unexpected "Text" after the JSON object`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ConvertMarkdownToHTML(tt.markdown, "{{ .Content }}", "")
+			td.CmpString(t, err, tt.expected)
+		})
+	}
+}
+
+func TestBraceStartingParagraphIsNotFrontMatter(t *testing.T) {
+	td.Cmp(t, GenerateHtmlBody("{not json} text"), "<p>{not json} text</p>\n")
+}
+
+func TestFrontMatterSchema(t *testing.T) {
+	schema, err := ParseFrontMatterSchema([]byte(`{"keys": {
+		"title": {"type": "string", "required": true},
+		"date": {"type": "date", "required": true},
+		"status": {"type": "enum", "values": ["draft", "published"]},
+		"tags": {"type": "list", "values": ["go", "delphi"]},
+		"tocLevels": {"type": "number"},
+		"draft": {"type": "boolean"}
+	}}`))
+	td.CmpNoError(t, err)
+
+	tests := []struct {
+		name        string
+		frontMatter string
+		expected    string
+	}{
+		{
+			name:        "01 Valid front matter",
+			frontMatter: "title: Post\ndate: 2026-03-13\nstatus: draft\ntags: [go]\ntocLevels: 2\ndraft: true\nextra: any",
+			expected:    "",
+		},
+		{
+			name:        "02 Date with time",
+			frontMatter: "title: Post\ndate: 2026-03-13T10:30:00+01:00",
+			expected:    "",
+		},
+		{
+			name:        "03 Invalid date",
+			frontMatter: "title: Post\ndate: 2026-13-45",
+			expected:    "front matter does not match the schema:\n  - date: \"2026-13-45\" is not a valid date, expected YYYY-MM-DD",
+		},
+		{
+			name:        "04 Missing and empty keys",
+			frontMatter: "title:\nstatus: draft",
+			expected:    "front matter does not match the schema:\n  - date: required key is missing\n  - title: required key is missing",
+		},
+		{
+			name:        "05 Value not allowed",
+			frontMatter: "title: Post\ndate: 2026-03-13\nstatus: deleted\ntags: [go, rust]",
+			expected:    "front matter does not match the schema:\n  - status: \"deleted\" is not one of draft, published\n  - tags: list item \"rust\" is not one of go, delphi",
+		},
+		{
+			name:        "06 Wrong types",
+			frontMatter: "title: [Post]\ndate: 2026\ntags: go\ntocLevels: two\ndraft: yes",
+			expected: "front matter does not match the schema:\n  - date: 2026 is not a valid date, expected YYYY-MM-DD\n" +
+				"  - draft: expected a boolean, got a string\n  - tags: expected a list, got a string\n" +
+				"  - title: expected a string, got a list\n  - tocLevels: expected a number, got a string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			markdown := "---\n" + tt.frontMatter + "\n---\nText"
+			_, err := ConvertMarkdownDocument(markdown, "{{ .Content }}", "", ConvertOptions{Schema: schema})
+			if tt.expected == "" {
+				td.CmpNoError(t, err)
+			} else {
+				td.CmpString(t, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFrontMatterSchemaWithoutFrontMatter(t *testing.T) {
+	schema := &FrontMatterSchema{Keys: map[string]SchemaKey{"author": {Required: true}}}
+
+	_, err := ConvertMarkdownDocument("# Title", "{{ .Content }}", "", ConvertOptions{Schema: schema})
+
+	td.CmpString(t, err, "front matter does not match the schema:\n  - author: required key is missing")
+}
+
+func TestParseFrontMatterSchemaErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		expected string
+	}{
+		{name: "01 Unknown type", schema: `{"keys": {"date": {"type": "datetime"}}}`, expected: `error parsing front matter schema: key "date" has unknown type "datetime"`},
+		{name: "02 Enum without values", schema: `{"keys": {"status": {"type": "enum"}}}`, expected: `error parsing front matter schema: enum key "status" has no values`},
+		{name: "03 Unknown field", schema: `{"keys": {"date": {"kind": "date"}}}`, expected: `error parsing front matter schema: json: unknown field "kind"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFrontMatterSchema([]byte(tt.schema))
+			td.CmpString(t, err, tt.expected)
+		})
+	}
+}
+
+func TestFrontMatterMeta(t *testing.T) {
+	markdown := strings.Join([]string{
+		"---",
+		`postId: "class-helpers"`,
+		`title: "Delphi: Class Helpers"`,
+		"tocLevels: 2",
+		"tags: [delphi, refactoring]",
+		"intro: >",
+		"  Short",
+		"  introduction",
+		"---",
+		"Text",
+	}, "\n")
+	template := `{{ .Title }}|{{ .TocLevels }}|{{ .Meta.postId }}|{{ range .Meta.tags }}#{{ . }} {{ end }}|{{ .Meta.intro }}`
+
+	result, err := ConvertMarkdownToHTML(markdown, template, "")
+
+	td.CmpNoError(t, err)
+	td.Cmp(t, result, "Delphi: Class Helpers|2|class-helpers|#delphi #refactoring |Short introduction\n")
+}
+
+func TestMalformedFrontMatterIsAnError(t *testing.T) {
+	markdown := "---\ntitle: Post\ntags: [a\n---\nText"
+
+	_, err := ConvertMarkdownToHTML(markdown, "{{ .Content }}", "")
+
+	td.CmpString(t, err, `error parsing front matter: line 3: unterminated flow collection, ']' expected`)
+}
+
+// ---------------------------------------------------------------------------
+// Document statistics
+// ---------------------------------------------------------------------------
+
+func TestDocumentStatistics(t *testing.T) {
+	markdown := "# Title\n\n![cover](cover.png)\n\nFirst *paragraph* with `code span` words.\n\n" +
+		"## Usage\n\n```go\nfunc main() {}\n```\n\n- one ![icon](icon.svg)\n- two\n\n" +
+		"| A | B |\n|---|---|\n| three | four |\n\n```\nplain code\n```\n"
+
+	data := TemplateData{}
+	generateHtmlBodyFromMarkdown(markdown, &data, DefaultConvertOptions())
+
+	td.Cmp(t, data, td.SStruct(TemplateData{
+		WordCount:      12,
+		ReadingMinutes: 1,
+		Excerpt:        "First paragraph with code span words.",
+		FirstImage:     "cover.png",
+		HeadingCount:   2,
+		CodeBlockCount: 2,
+		ImageCount:     2,
+	}, td.StructFields{
+		"Content": td.Ignore(), "TOC": td.Ignore(), "Footnotes": td.Ignore(), "Headings": td.Ignore(),
+	}))
+}
+
+func TestDocumentStatisticsCountRenderedContentOnly(t *testing.T) {
+	markdown := "> Quoted words first\n\nSee note[^used].\n\n- item\n\n  [^nested]: nested unused note\n\n" +
+		"[^used]: Used note.\n[^unused]: never referenced words here\n"
+
+	data := TemplateData{}
+	generateHtmlBodyFromMarkdown(markdown, &data, DefaultConvertOptions())
+
+	td.Cmp(t, data.WordCount, 8)
+	td.Cmp(t, data.Excerpt, "See note.")
+}
+
+func TestReadingMinutesDependOnLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		words    int
+		language string
+		expected int
+	}{
+		{name: "01 English", words: 456, language: "en", expected: 2},
+		{name: "02 Polish is read slower", words: 456, language: "pl", expected: 3},
+		{name: "03 Region is ignored", words: 456, language: "pl-PL", expected: 3},
+		{name: "04 Unknown language", words: 456, language: "", expected: 3},
+		{name: "05 At least one minute", words: 0, language: "en", expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, readingMinutes(tt.words, tt.language), tt.expected)
+		})
+	}
+}
+
+func TestDocumentStatisticsInTemplate(t *testing.T) {
+	markdown := "---\nlanguage: pl\n---\nKod **czytelny** dzięki `helper` klasom.\n\n![Wiewiórka](squirrel.png)"
+	template := `{{ .WordCount }} words, {{ .ReadingMinutes }} min, {{ .ImageCount }} image {{ .FirstImage }}: {{ .Excerpt }}`
+
+	result, err := ConvertMarkdownToHTML(markdown, template, "")
+
+	td.CmpNoError(t, err)
+	td.Cmp(t, result, "4 words, 1 min, 1 image squirrel.png: Kod czytelny dzięki helper klasom.")
+}
+
+// ---------------------------------------------------------------------------
+// Document tree
+// ---------------------------------------------------------------------------
+
+func TestParseMarkdownBuildsDocumentTree(t *testing.T) {
+	markdown := strings.Join([]string{
+		"# Title with `code`",
+		"```go",
+		"x := 1",
+		"```",
+		"",
+		"- First **bold**",
+		"  - Nested [link](https://example.com)",
+		"> Note: quoted *text*",
+		"![figure: Tux](tux.png)",
+	}, "\n")
+
+	doc := parseMarkdown(markdown, DefaultConvertOptions(), nil)
+
+	td.Cmp(t, doc, &Document{Children: []Block{
+		&Heading{Level: 1, ID: "title-with-code", Content: InlineContent{
+			Raw:     "Title with `code`",
+			Inlines: []Inline{&Text{Value: "Title with "}, &Code{Value: "code"}},
+		}},
+		&CodeBlock{Language: "go", Lines: []string{"x := 1"}},
+		&BlankLine{},
+		&List{Items: []*ListItem{
+			{
+				Content: InlineContent{
+					Raw:     "First **bold**",
+					Inlines: []Inline{&Text{Value: "First "}, &Strong{Children: []Inline{&Text{Value: "bold"}}}},
+				},
+				Children: []Block{&List{Items: []*ListItem{
+					{Content: InlineContent{
+						Raw: "Nested [link](https://example.com)",
+						Inlines: []Inline{
+							&Text{Value: "Nested "},
+							&Link{Destination: "https://example.com", Children: []Inline{&Text{Value: "link"}}},
+						},
+					}},
+				}}},
+			},
+		}},
+		&BlockQuote{
+			Callout: &InlineContent{Raw: "Note:", Inlines: []Inline{&Text{Value: "Note:"}}},
+			Children: []Block{&Paragraph{Content: InlineContent{
+				Raw:     "quoted *text*",
+				Inlines: []Inline{&Text{Value: "quoted "}, &Emphasis{Children: []Inline{&Text{Value: "text"}}}},
+			}}},
+		},
+		&Paragraph{Content: InlineContent{
+			Raw:     "![figure: Tux](tux.png)",
+			Inlines: []Inline{&Image{Source: "tux.png", Alt: "figure: Tux"}},
+		}},
+	}})
+}
+
 // ---------------------------------------------------------------------------
 // Edge cases and malformed input
 // ---------------------------------------------------------------------------
@@ -916,6 +3246,11 @@ func TestEdgeCases(t *testing.T) {
 			markdown:       strings.Repeat("a", 10000),
 			shouldNotCrash: true,
 		},
+		{
+			name:           "04 List starting deeper than the following item",
+			markdown:       "  - Indented first\n- Second",
+			shouldNotCrash: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -941,7 +3276,7 @@ func TestConvertWithTemplate(t *testing.T) {
 
 	result, err := ConvertMarkdownToHTML(markdown, template, title)
 
-	expected := "<html><head><title>TestABC</title></head><body><h1>Hello World</h1>\n\n<p>Generate HTML page</p>\n</body></html>"
+	expected := "<html><head><title>TestABC</title></head><body><h1 id=\"hello-world\">Hello World</h1>\n\n<p>Generate HTML page</p>\n</body></html>"
 	td.Cmp(t, err, nil)
 	td.Cmp(t, result, expected)
 }
@@ -966,7 +3301,7 @@ Generate HTML page`
 
 	td.Cmp(t, err, nil)
 	td.Cmp(t, result, `<html><head><title>Front Matter Title</title><meta name="description" content="A document description"></head><body><span class="date">2026-03-13</span><span class="author">Bogdan Polak</span><span class="language">pl</span><img src="cover.png" alt="Cover caption"><footer></footer><article>
-<h1>Hello World</h1>
+<h1 id="hello-world">Hello World</h1>
 
 <p>Generate HTML page</p>
 </article></body></html>`)
@@ -1007,13 +3342,44 @@ func TestConvertWithTemplateTitlePrecedence(t *testing.T) {
 			td.Cmp(t, result, td.All(
 				td.Contains("<title>"+tt.expectedTitle+"</title>"),
 				td.Contains("<article>"),
-				td.Contains("<h1>Hello</h1>"),
+				td.Contains("<h1 id=\"hello\">Hello</h1>"),
 				td.Not(td.Contains("---")),
 			))
 		})
 	}
 }
 
+func TestConvertWithTemplateEscapesMetadata(t *testing.T) {
+	markdown := "---\n" +
+		"title: \"<script>alert(1)</script>\"\n" +
+		"description: 'Say \"hi\" & bye'\n" +
+		"coverImage: \"javascript:alert(1)\"\n" +
+		"---\n\n" +
+		"## Intro <b>bold</b>"
+	template := `<title>{{ .Title }}</title><meta name="description" content="{{ .Description }}"><img src="{{ .CoverImage }}">{{ .TOC }}{{ .Content }}`
+
+	result, err := ConvertMarkdownToHTML(markdown, template, "")
+
+	td.Cmp(t, err, nil)
+	td.Cmp(t, result, `<title>&lt;script&gt;alert(1)&lt;/script&gt;</title>`+
+		`<meta name="description" content="Say &#34;hi&#34; &amp; bye">`+
+		`<img src="#ZgotmplZ">`+
+		`<nav class="toc">`+"\n"+`<ul>`+"\n"+`    <li><a href="#intro-bold">Intro bold</a></li>`+"\n"+`</ul>`+"\n"+`</nav>`+"\n\n"+
+		`<h2 id="intro-bold">Intro <b>bold</b></h2>`+"\n")
+}
+
+func TestConvertWithTextTemplateCompatibility(t *testing.T) {
+	markdown := "---\ntitle: \"<b>Raw</b> title\"\n---\n\nText"
+	template := `<title>{{ .Title }}</title>{{ .Content }}`
+	options := DefaultConvertOptions()
+	options.TextTemplate = true
+
+	result, err := ConvertMarkdownToHTMLWithOptions(markdown, template, "", options)
+
+	td.Cmp(t, err, nil)
+	td.Cmp(t, result, "<title><b>Raw</b> title</title>\n<p>Text</p>\n")
+}
+
 func TestComplexDocument(t *testing.T) {
 	markdown := `# Main Title
 
@@ -1046,14 +3412,14 @@ Visit https://github.com for more info.`
 
 	result = strings.ReplaceAll(result, "\n", "│")
 	td.Cmp(t, result, td.All(
-		td.Contains("<h1>Main Title</h1>"),
+		td.Contains("<h1 id=\"main-title\">Main Title</h1>"),
 		td.Contains("<p>This is an introduction with a <a href=\"https://example.com\">link</a> and some <code>inline code</code>.</p>"),
-		td.Contains("<h2>Section 1</h2>"),
+		td.Contains("<h2 id=\"section-1\">Section 1</h2>"),
 		td.Contains("<p>Some text with <code>npm install</code> command.</p>"),
 		td.Contains("<ul>│    <li>Install dependencies</li>│    <li>Run tests</li>│    <li>Deploy</li>│</ul>"),
-		td.Contains("<h2>Section 2</h2>"),
-		td.Contains("<h3>Nested subsection</h3>"),
-		td.Contains("<h4>Level four - Nested subsection</h4>"),
+		td.Contains("<h2 id=\"section-2\">Section 2</h2>"),
+		td.Contains("<h3 id=\"nested-subsection\">Nested subsection</h3>"),
+		td.Contains("<h4 id=\"level-four-nested-subsection\">Level four - Nested subsection</h4>"),
 		td.Contains(
 			strings.Join([]string{
 				"<ol>",
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var entityPattern = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+
+// parseEntity reads a named or numeric character reference such as "&copy;", "&#8222;"
+// or "&#x201E;". Names unknown to HTML are not entities, so "&foo;" stays plain text.
+func parseEntity(text string) (Inline, int) {
+	entity := entityPattern.FindString(text)
+	if entity == "" || (entity[1] != '#' && html.UnescapeString(entity) == entity) {
+		return nil, 0
+	}
+
+	return &Entity{Literal: entity}, len(entity)
+}
+
+// unescapeMarkdown resolves backslash escapes and character references in link
+// destinations, titles and code fence info strings; the result is plain text
+func unescapeMarkdown(text string) string {
+	if !strings.ContainsAny(text, "\\&") {
+		return text
+	}
+
+	var plain strings.Builder
+	for pos := 0; pos < len(text); pos++ {
+		switch {
+		case text[pos] == '\\' && pos+1 < len(text) && isASCIIPunctuation(text[pos+1]):
+			pos++
+			plain.WriteByte(text[pos])
+		case text[pos] == '&':
+			if node, length := parseEntity(text[pos:]); node != nil {
+				plain.WriteString(html.UnescapeString(node.(*Entity).Literal))
+				pos += length - 1
+				continue
+			}
+			plain.WriteByte('&')
+		default:
+			plain.WriteByte(text[pos])
+		}
+	}
+
+	return plain.String()
+}
+
+// indexUnescaped is strings.IndexAny skipping characters escaped with a backslash
+func indexUnescaped(text string, chars string) int {
+	for pos := 0; pos < len(text); pos++ {
+		if text[pos] == '\\' {
+			pos++
+		} else if strings.IndexByte(chars, text[pos]) >= 0 {
+			return pos
+		}
+	}
+
+	return -1
+}
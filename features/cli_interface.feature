@@ -7,17 +7,17 @@ Feature: CLI Interface
     Given I have a markdown file "input.md" with content "# Hello"
     When I run the command "md2html -input input.md -output output.html"
     Then a file "output.html" should be created
-    And the file should contain "<h1>Hello</h1>"
+    And the file should contain "<h1 id=\"hello\">Hello</h1>"
 
   Scenario: CLI 002 Convert from stdin to stdout
     Given I have markdown content "# From Stdin"
     When I pipe the content to md2html
-    Then I should get HTML output containing "<h1>From Stdin</h1>"
+    Then I should get HTML output containing "<h1 id=\"from-stdin\">From Stdin</h1>"
 
   Scenario: CLI 003 Convert file to stdout
     Given I have a markdown file "test.md" with content "## Test Header"
     When I run the command "md2html -input test.md"
-    Then I should get HTML output containing "<h2>Test Header</h2>"
+    Then I should get HTML output containing "<h2 id=\"test-header\">Test Header</h2>"
 
   Scenario: CLI 004 Use custom title
     Given I have a markdown file "doc.md" with content "# Document"
@@ -32,7 +32,7 @@ Feature: CLI Interface
       """
     When I run the command "md2html -input content.md -template template.html"
     Then the HTML output should contain "<html><body>"
-    And the HTML output should contain "<h1>Content</h1>"
+    And the HTML output should contain "<h1 id=\"content\">Content</h1>"
 
   Scenario: CLI 006 Show help information
     When I run the command "md2html -help"
@@ -41,6 +41,7 @@ Feature: CLI Interface
     And I should see help text containing "-output"
     And I should see help text containing "-template"
     And I should see help text containing "-title"
+    And I should see help text containing "-anchors"
 
   Scenario: CLI 007 Handle missing input file
     When I run the command "md2html -input nonexistent.md"
@@ -82,4 +83,126 @@ Feature: CLI Interface
     And the HTML output should contain "<span>pl</span>"
     And the HTML output should contain "<img src=\"cover.png\" alt=\"Cover caption\">"
     And the HTML output should contain "<footer></footer>"
-    And the HTML output should contain "<h1>Hello World</h1>"
\ No newline at end of file
+    And the HTML output should contain "<h1 id=\"hello-world\">Hello World</h1>"
+
+  Scenario: CLI 010 Add self-link anchors to headings
+    Given I have a markdown file "doc.md" with content "## Fragmentacja Intencji"
+    When I run the command "md2html -input doc.md -anchors"
+    Then the HTML output should contain "<h2 id=\"fragmentacja-intencji\"><a class=\"anchor\" href=\"#fragmentacja-intencji\" aria-hidden=\"true\">#</a>Fragmentacja Intencji</h2>"
+  Scenario: CLI 011 Inline extensions are enabled by default
+    Given I have a markdown file "doc.md" with content "Press [[Ctrl+C]] to ~~quit~~ copy"
+    When I run the command "md2html -input doc.md"
+    Then the HTML output should contain "<p>Press <kbd>Ctrl+C</kbd> to <del>quit</del> copy</p>"
+
+  Scenario: CLI 012 Disable an inline extension
+    Given I have a markdown file "doc.md" with content "Press [[Ctrl+C]] to ~~quit~~ copy"
+    When I run the command "md2html -input doc.md -strikethrough=false"
+    Then the HTML output should contain "<p>Press <kbd>Ctrl+C</kbd> to ~~quit~~ copy</p>"
+
+  Scenario: CLI 013 Raw HTML is passed through by default
+    Given I have a markdown file "doc.md" with content:
+      """
+      Some <span class="x">tagged</span> text
+      """
+    When I run the command "md2html -input doc.md"
+    Then the HTML output should contain "<p>Some <span class=\"x\">tagged</span> text</p>"
+
+  Scenario: CLI 014 Sanitize raw HTML in safe mode
+    Given I have a markdown file "doc.md" with content:
+      """
+      Some <span class="x" onclick="steal()">tagged</span> <iframe src="x"></iframe>text
+      """
+    When I run the command "md2html -input doc.md -safe"
+    Then the HTML output should contain "<p>Some <span class=\"x\">tagged</span> text</p>"
+    And the error output should contain "Warning: removed onclick attribute from <span>: attribute is not allowed"
+    And the error output should contain "Warning: removed <iframe>: tag is not allowed"
+
+  Scenario: CLI 015 Sanitizer policy file
+    Given I have a markdown file "doc.md" with content "[a](http://a.pl) [b](https://b.pl)"
+    And I have a template file "policy.json" with content:
+      """
+      {"tags": {"a": ["href"]}, "urlSchemes": ["https"]}
+      """
+    When I run the command "md2html -input doc.md -policy policy.json"
+    Then the HTML output should contain "<p><a>a</a> <a href=\"https://b.pl\">b</a></p>"
+
+  Scenario: CLI 016 Escape front matter metadata in the template
+    Given I have a markdown file "post.md" with content:
+      """
+      ---
+      title: "<script>alert(1)</script>"
+      coverImage: "javascript:alert(1)"
+      ---
+
+      Text
+      """
+    And I have a template file "template.html" with content:
+      """
+      <title>{{.Title}}</title><img src="{{.CoverImage}}">{{.Content}}
+      """
+    When I run the command "md2html -input post.md -template template.html"
+    Then the HTML output should contain "<title>&lt;script&gt;alert(1)&lt;/script&gt;</title><img src=\"#ZgotmplZ\">"
+    And the HTML output should contain "<p>Text</p>"
+
+  Scenario: CLI 017 Keep raw metadata with text/template
+    Given I have a markdown file "post.md" with content:
+      """
+      ---
+      title: "<b>Raw</b>"
+      ---
+
+      Text
+      """
+    And I have a template file "template.html" with content:
+      """
+      <title>{{.Title}}</title>
+      """
+    When I run the command "md2html -input post.md -template template.html -text-template"
+    Then the HTML output should contain "<title><b>Raw</b></title>"
+
+  Scenario: CLI 018 Validate front matter against a schema
+    Given I have a markdown file "post.md" with content:
+      """
+      ---
+      title: Post
+      date: 2026-13-45
+      ---
+
+      Text
+      """
+    And I have a template file "schema.json" with content:
+      """
+      {"keys": {"date": {"type": "date", "required": true}, "author": {"type": "string", "required": true}}}
+      """
+    When I run the command "md2html -input post.md -schema schema.json"
+    Then I should get an error message containing "front matter does not match the schema"
+    And I should get an error message containing "- author: required key is missing"
+    And I should get an error message containing "- date: \"2026-13-45\" is not a valid date, expected YYYY-MM-DD"
+    And the command should exit with code 1
+
+  Scenario: CLI 019 Render with layouts and partials from a template directory
+    Given I have a markdown file "post.md" with content:
+      """
+      ---
+      title: Class Helpers
+      layout: post
+      ---
+
+      Text
+      """
+    And I have a template file "site/layouts/base.html" with content:
+      """
+      <html><head><title>{{.Title}}</title></head><body>{{template "nav.html" .}}{{block "main" .}}{{.Content}}{{end}}</body></html>
+      """
+    And I have a template file "site/layouts/post.html" with content:
+      """
+      {{define "main"}}<article>{{.Content}}</article>{{end}}
+      """
+    And I have a template file "site/partials/nav.html" with content:
+      """
+      <nav>Blog</nav>
+      """
+    When I run the command "md2html -input post.md -templates site"
+    Then the HTML output should contain "<title>Class Helpers</title>"
+    And the HTML output should contain "<nav>Blog</nav><article>"
+    And the HTML output should contain "<p>Text</p>"
@@ -9,7 +9,7 @@ Feature: Template Processing
       # Hello World
       """
     When I convert it to HTML
-    Then I should get HTML containing "<h1>Hello World</h1>"
+    Then I should get HTML containing "<h1 id=\"hello-world\">Hello World</h1>"
 	
   Scenario: Processing 002 Use custom template with title
     Given I have a title "Generated document v1" with a template content:
@@ -48,5 +48,5 @@ Feature: Template Processing
       This is the article content with **bold** text.
       """
     When I convert it to HTML
-    Then I should get HTML containing "<h1>Hello World</h1>"
+    Then I should get HTML containing "<h1 id=\"hello-world\">Hello World</h1>"
     And I should get HTML containing "<p>This is the article content with <strong>bold</strong> text.</p>"
\ No newline at end of file
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var footnoteDefinitionPattern = regexp.MustCompile(`^ {0,3}\[\^([^\]\s]+)\]:[ \t]*(.*)$`)
+
+const footnoteContentIndent = 4
+
+func isFootnoteDefinitionLine(ln string) bool {
+	return footnoteDefinitionPattern.MatchString(ln)
+}
+
+// parseFootnoteDefinition reads a "[^label]: text" definition. The text continues on
+// following lines until a new block starts; after a blank line only lines indented
+// by four spaces belong to the definition, so it can hold several paragraphs.
+func parseFootnoteDefinition(lineIdx int, lines []string) (int, *FootnoteDefinition) {
+	match := footnoteDefinitionPattern.FindStringSubmatch(lines[lineIdx])
+	contentLines := []string{match[2]}
+
+	lineIdx++
+	for lineIdx < len(lines) {
+		ln := lines[lineIdx]
+		if isBlankLine(ln) {
+			nextIdx := skipBlankLines(lines, lineIdx)
+			if nextIdx == len(lines) || getLineDepth(lines[nextIdx]) < footnoteContentIndent {
+				break
+			}
+			for ; lineIdx < nextIdx; lineIdx++ {
+				contentLines = append(contentLines, "")
+			}
+			continue
+		}
+
+		if getLineDepth(ln) >= footnoteContentIndent {
+			contentLines = append(contentLines, removeIndentation(ln, footnoteContentIndent))
+		} else if contentLines[len(contentLines)-1] != "" && !startsNewBlock(lines, lineIdx) {
+			contentLines = append(contentLines, ln)
+		} else {
+			break
+		}
+		lineIdx++
+	}
+
+	// Blank lines after the definition belong to it, so the removed definition leaves no gap
+	return skipBlankLines(lines, lineIdx), &FootnoteDefinition{
+		Label:    match[1],
+		Children: trimBlankLines(parseBlocks(contentLines)),
+	}
+}
+
+// removeIndentation drops up to width columns of leading spaces and tabs
+func removeIndentation(ln string, width int) string {
+	depth := 0
+	for idx, char := range ln {
+		if depth >= width || (char != ' ' && char != '\t') {
+			return ln[idx:]
+		}
+		if char == '\t' {
+			depth += 4
+		} else {
+			depth++
+		}
+	}
+
+	return ""
+}
+
+// resolveFootnotes numbers the footnotes in the order of their first reference and
+// links every reference with its definition. Definitions never referenced are dropped
+// and references without a definition stay literal text.
+func resolveFootnotes(doc *Document) {
+	definitions := map[string]*FootnoteDefinition{}
+	walkBlocks(doc.Children, func(block Block) {
+		if definition, ok := block.(*FootnoteDefinition); ok && definitions[definition.Label] == nil {
+			definitions[definition.Label] = definition
+		}
+	})
+
+	forEachInlineContent(doc.Children, func(content *InlineContent) {
+		walkInlines(content.Inlines, func(inline Inline) {
+			reference, ok := inline.(*FootnoteReference)
+			if !ok || definitions[reference.Label] == nil {
+				return
+			}
+
+			definition := definitions[reference.Label]
+			if definition.Number == 0 {
+				doc.Footnotes = append(doc.Footnotes, definition)
+				definition.Number = len(doc.Footnotes)
+			}
+			definition.ReferenceCount++
+			reference.Definition = definition
+			reference.Index = definition.ReferenceCount
+		})
+	})
+
+	for _, definition := range doc.Footnotes {
+		appendFootnoteBackReferences(definition)
+	}
+}
+
+// appendFootnoteBackReferences puts the links back to the references at the end of the
+// last paragraph of the definition
+func appendFootnoteBackReferences(definition *FootnoteDefinition) {
+	var backReferences []Inline
+	for index := 1; index <= definition.ReferenceCount; index++ {
+		backReferences = append(backReferences, &Text{Value: " "}, &FootnoteBackReference{Number: definition.Number, Index: index})
+	}
+
+	if len(definition.Children) > 0 {
+		if paragraph, ok := definition.Children[len(definition.Children)-1].(*Paragraph); ok {
+			paragraph.Content.Inlines = append(paragraph.Content.Inlines, backReferences...)
+			return
+		}
+	}
+
+	paragraph := &Paragraph{Content: InlineContent{Inlines: backReferences[1:]}}
+	definition.Children = append(definition.Children, paragraph)
+}
+
+func renderFootnotes(doc *Document, data *TemplateData, options ConvertOptions, sanitizer *htmlSanitizer) string {
+	if len(doc.Footnotes) == 0 {
+		return ""
+	}
+
+	renderer := &htmlRenderer{language: data.Language, options: options, sanitizer: sanitizer}
+	renderer.out.WriteString("<section class=\"footnotes\">\n<ol>\n")
+	for _, definition := range doc.Footnotes {
+		fmt.Fprintf(&renderer.out, "%s<li id=\"%s\">\n", createIndentation(1), buildFootnoteID(definition.Number))
+		renderer.renderBlocks(definition.Children)
+		renderer.out.WriteString(createIndentation(1) + "</li>\n")
+	}
+	renderer.out.WriteString("</ol>\n</section>\n")
+	return renderer.out.String()
+}
+
+func (r *htmlRenderer) renderFootnoteReference(reference *FootnoteReference) {
+	if reference.Definition == nil {
+		r.out.WriteString(escapeHTML("[^" + reference.Label + "]"))
+		return
+	}
+
+	number := reference.Definition.Number
+	fmt.Fprintf(&r.out, "<sup class=\"footnote-ref\"><a href=\"#%s\" id=\"%s\">%d</a></sup>",
+		buildFootnoteID(number), buildFootnoteReferenceID(number, reference.Index), number)
+}
+
+func (r *htmlRenderer) renderFootnoteBackReference(backReference *FootnoteBackReference) {
+	fmt.Fprintf(&r.out, "<a href=\"#%s\" class=\"footnote-backref\" aria-label=\"Back to reference %d\">↩",
+		buildFootnoteReferenceID(backReference.Number, backReference.Index), backReference.Number)
+	if backReference.Index > 1 {
+		fmt.Fprintf(&r.out, "<sup>%d</sup>", backReference.Index)
+	}
+	r.out.WriteString("</a>")
+}
+
+func buildFootnoteID(number int) string {
+	return fmt.Sprintf("fn-%d", number)
+}
+
+// The first reference is "fnref-N", further references to the same footnote get a "-2", "-3" suffix
+func buildFootnoteReferenceID(number int, index int) string {
+	if index > 1 {
+		return fmt.Sprintf("fnref-%d-%d", number, index)
+	}
+
+	return fmt.Sprintf("fnref-%d", number)
+}
+
+func parseFootnoteReference(text string) (Inline, int) {
+	end := strings.IndexByte(text, ']')
+	if end <= 2 || strings.ContainsAny(text[2:end], " \t\n") {
+		return nil, 0
+	}
+
+	return &FootnoteReference{Label: text[2:end]}, end + 1
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const yamlFrontMatterDelimiter = "---"
+const tomlFrontMatterDelimiter = "+++"
+
+// frontMatterDateLayouts are the accepted forms of a date value
+var frontMatterDateLayouts = []string{"2006-01-02", time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02 15:04"}
+
+func parseFrontMatterDate(text string) (time.Time, bool) {
+	for _, layout := range frontMatterDateLayouts {
+		if parsed, err := time.Parse(layout, text); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// frontMatterError is a malformed front matter; Line counts from the first line of the file
+type frontMatterError struct {
+	Line    int
+	Message string
+}
+
+func (e *frontMatterError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// parseLeadingFrontMatter splits the front matter from the markdown body. It is YAML
+// between "---" lines, TOML between "+++" lines or a JSON object starting on the first line.
+// A malformed front matter is reported with its line number, the body is returned anyway.
+func parseLeadingFrontMatter(markdown string) (string, TemplateData, error) {
+	lines := strings.Split(markdown, "\n")
+	firstLine := strings.TrimSpace(lines[0])
+
+	var body string
+	var meta map[string]any
+	var err error
+	switch {
+	case firstLine == yamlFrontMatterDelimiter:
+		closingIdx := findFrontMatterClosingLine(lines, yamlFrontMatterDelimiter)
+		if closingIdx < 0 {
+			// without the closing line it is a thematic break
+			return markdown, TemplateData{}, nil
+		}
+		body = strings.Join(lines[closingIdx+1:], "\n")
+		meta, err = parseYAMLFrontMatter(lines[1:closingIdx], 2)
+	case firstLine == tomlFrontMatterDelimiter:
+		closingIdx := findFrontMatterClosingLine(lines, tomlFrontMatterDelimiter)
+		if closingIdx < 0 {
+			return markdown, TemplateData{}, &frontMatterError{Line: 1, Message: "front matter is not closed with \"+++\""}
+		}
+		body = strings.Join(lines[closingIdx+1:], "\n")
+		meta, err = parseTOMLFrontMatter(lines[1:closingIdx], 2)
+	case firstLine == "{" || strings.HasPrefix(firstLine, "{\""):
+		body, meta, err = parseJSONFrontMatter(markdown)
+	default:
+		return markdown, TemplateData{}, nil
+	}
+
+	if err != nil {
+		return body, TemplateData{}, err
+	}
+	return body, extractTemplateDataFromFrontMatter(meta), nil
+}
+
+func findFrontMatterClosingLine(lines []string, delimiter string) int {
+	for lineIdx := 1; lineIdx < len(lines); lineIdx++ {
+		if strings.TrimSpace(lines[lineIdx]) == delimiter {
+			return lineIdx
+		}
+	}
+
+	return -1
+}
+
+// parseJSONFrontMatter reads the JSON object at the start of markdown; the body starts
+// on the line after the closing brace
+func parseJSONFrontMatter(markdown string) (string, map[string]any, error) {
+	decoder := json.NewDecoder(strings.NewReader(markdown))
+	decoder.UseNumber()
+
+	var meta map[string]any
+	if err := decoder.Decode(&meta); err != nil {
+		var syntaxError *json.SyntaxError
+		switch {
+		case errors.As(err, &syntaxError):
+			return markdown, nil, &frontMatterError{Line: lineNumberAt(markdown, int(syntaxError.Offset)), Message: syntaxError.Error()}
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			return markdown, nil, &frontMatterError{Line: 1, Message: "JSON object is not closed"}
+		default:
+			return markdown, nil, &frontMatterError{Line: 1, Message: err.Error()}
+		}
+	}
+
+	end := int(decoder.InputOffset())
+	rest, body, _ := strings.Cut(markdown[end:], "\n")
+	if strings.TrimSpace(rest) != "" {
+		return body, nil, &frontMatterError{Line: lineNumberAt(markdown, end), Message: fmt.Sprintf("unexpected %q after the JSON object", strings.TrimSpace(rest))}
+	}
+
+	return body, normalizeJSONValue(meta).(map[string]any), nil
+}
+
+// lineNumberAt returns the line number of the byte offset in text
+func lineNumberAt(text string, offset int) int {
+	return 1 + strings.Count(text[:min(offset, len(text))], "\n")
+}
+
+// normalizeJSONValue turns JSON numbers into int or float64, like the other formats
+func normalizeJSONValue(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if number, err := v.Int64(); err == nil {
+			return int(number)
+		}
+		number, _ := v.Float64()
+		return number
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalizeJSONValue(item)
+		}
+	case []any:
+		for idx, item := range v {
+			v[idx] = normalizeJSONValue(item)
+		}
+	}
+
+	return value
+}
+
+// extractTemplateDataFromFrontMatter fills the named template fields from scalar values;
+// all keys stay available in Meta
+func extractTemplateDataFromFrontMatter(meta map[string]any) TemplateData {
+	data := TemplateData{Meta: meta}
+
+	for key, value := range meta {
+		text, ok := frontMatterText(value)
+		if !ok {
+			continue
+		}
+
+		setTemplateDataField(&data, key, text)
+	}
+
+	return data
+}
+
+// frontMatterText formats a scalar value; lists and maps are not text
+func frontMatterText(value any) (string, bool) {
+	switch v := value.(type) {
+	case nil:
+		return "", true
+	case string:
+		return v, true
+	case int, float64, bool:
+		return fmt.Sprint(v), true
+	}
+
+	return "", false
+}
+
+func setTemplateDataField(data *TemplateData, key, value string) {
+	switch key {
+	case "title":
+		data.Title = value
+	case "description":
+		data.Description = value
+	case "date":
+		data.Date = value
+		data.ParsedDate, _ = parseFrontMatterDate(value)
+	case "author":
+		data.Author = value
+	case "language":
+		data.Language = value
+	case "coverImage":
+		data.CoverImage = value
+	case "coverImageCaption":
+		data.CoverImageCaption = value
+	case "pageFooter":
+		data.PageFooter = value
+	case "layout":
+		data.Layout = value
+	case "tocLevels":
+		data.TocLevels = value
+	}
+}
@@ -0,0 +1,699 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The inline parser follows the CommonMark delimiter stack algorithm. Text is scanned once,
+// left to right: code spans, escapes, autolinks and line breaks become nodes right away,
+// while "*" and "_" runs and "[" / "![" openers are kept as plain text nodes and recorded
+// on the delimiter and bracket stacks. A "]" tries to close the latest bracket as a link
+// or image; emphasis is resolved between matching delimiter runs once the content is known.
+
+// Autolinks in angle brackets: "<https://example.com>" and "<user@example.com>"
+var uriAutoLinkPattern = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9+.-]{1,31}:[^<>\x00-\x20]*)>`)
+var emailAutoLinkPattern = regexp.MustCompile(`^<([A-Za-z0-9.!#$%&'*+/=?^_` + "`" + `{|}~-]+@[A-Za-z0-9](?:[A-Za-z0-9-]{0,61}[A-Za-z0-9])?(?:\.[A-Za-z0-9](?:[A-Za-z0-9-]{0,61}[A-Za-z0-9])?)*)>`)
+
+// delimiter is a run of "*" or "_" that may open or close emphasis, or a "~~" or "=="
+// run of the strikethrough and highlight extensions
+type delimiter struct {
+	node      *Text
+	char      byte
+	count     int // characters left in the run
+	origCount int
+	canOpen   bool
+	canClose  bool
+}
+
+// bracket is a "[" or "![" waiting for its "]"
+type bracket struct {
+	node            *Text
+	isImage         bool
+	active          bool // false once the opener can no longer form a link
+	delimitersBelow int  // emphasis inside the brackets starts above this delimiter index
+	labelStart      int  // position of the text after "[" in the source
+}
+
+type inlineParser struct {
+	text       string
+	references linkReferences
+	options    ConvertOptions // enabled inline extensions
+	nodes      []Inline
+	delimiters []*delimiter
+	brackets   []*bracket
+	plain      strings.Builder
+}
+
+// parseInlines splits the raw text of a block into inline nodes. References resolve
+// reference-style links and images, options select the inline extensions.
+func parseInlines(text string, references linkReferences, options ConvertOptions) []Inline {
+	parser := &inlineParser{text: text, references: references, options: options}
+	for pos := 0; pos < len(text); {
+		pos = parser.parseAt(pos)
+	}
+	parser.flushPlainText()
+	parser.processEmphasis(-1)
+
+	return mergeTextNodes(parser.nodes)
+}
+
+// parseAt consumes the inline element at pos and returns the position after it
+func (p *inlineParser) parseAt(pos int) int {
+	rest := p.text[pos:]
+
+	switch {
+	case rest[0] == '\n':
+		p.addLineBreak()
+		return pos + 1
+	case rest[0] == '\\':
+		return p.parseBackslash(pos)
+	case rest[0] == '&':
+		if node, length := parseEntity(rest); node != nil {
+			p.addNode(node)
+			return pos + length
+		}
+	case rest[0] == '`':
+		return p.parseCodeSpan(pos)
+	case rest[0] == '<':
+		if node, length := parseAngleAutoLink(rest); node != nil {
+			p.addNode(node)
+			return pos + length
+		}
+		if node, length := parseInlineHTML(rest); node != nil {
+			p.addNode(node)
+			return pos + length
+		}
+	case rest[0] == '*' || rest[0] == '_':
+		return p.parseDelimiterRun(pos)
+	case rest[0] == '~' || rest[0] == '=' || rest[0] == '^':
+		return p.parseExtensionMarker(pos)
+	case strings.HasPrefix(rest, "[[") && p.options.Keyboard:
+		if node, length := parseKeyboard(rest); node != nil {
+			p.addNode(node)
+			return pos + length
+		}
+		return p.addBracket(pos, 1, false)
+	case strings.HasPrefix(rest, "[^"):
+		if node, length := parseFootnoteReference(rest); node != nil {
+			p.addNode(node)
+			return pos + length
+		}
+		return p.addBracket(pos, 1, false)
+	case strings.HasPrefix(rest, "!["):
+		return p.addBracket(pos, 2, true)
+	case rest[0] == '[':
+		return p.addBracket(pos, 1, false)
+	case rest[0] == ']':
+		return p.closeBracket(pos)
+	case strings.HasPrefix(rest, "http://") || strings.HasPrefix(rest, "https://"):
+		if node, length := parseAutoLink(rest); node != nil {
+			p.addNode(node)
+			return pos + length
+		}
+	}
+
+	p.plain.WriteByte(rest[0])
+	return pos + 1
+}
+
+func (p *inlineParser) flushPlainText() {
+	if p.plain.Len() > 0 {
+		p.nodes = append(p.nodes, &Text{Value: p.plain.String()})
+		p.plain.Reset()
+	}
+}
+
+func (p *inlineParser) addNode(node Inline) {
+	p.flushPlainText()
+	p.nodes = append(p.nodes, node)
+}
+
+// addLineBreak ends a line; two or more trailing spaces turn it into a hard break
+func (p *inlineParser) addLineBreak() {
+	line := p.plain.String()
+	withoutTrailingSpaces := strings.TrimRight(line, " ")
+	p.plain.Reset()
+	p.plain.WriteString(withoutTrailingSpaces)
+
+	if len(line)-len(withoutTrailingSpaces) >= 2 {
+		p.addNode(&HardBreak{})
+	} else {
+		p.addNode(&SoftBreak{})
+	}
+}
+
+// parseBackslash handles "\" before a line ending (hard break) or ASCII punctuation (literal)
+func (p *inlineParser) parseBackslash(pos int) int {
+	if pos+1 < len(p.text) {
+		next := p.text[pos+1]
+		if next == '\n' {
+			p.addNode(&HardBreak{})
+			return pos + 2
+		}
+		if isASCIIPunctuation(next) {
+			p.plain.WriteByte(next)
+			return pos + 2
+		}
+	}
+
+	p.plain.WriteByte('\\')
+	return pos + 1
+}
+
+// parseCodeSpan reads a code span opened by a run of backticks and closed by a run of the
+// same length. One space on both sides of the content is dropped, so "“ `a` “" gives "`a`".
+func (p *inlineParser) parseCodeSpan(pos int) int {
+	runLength := countRun(p.text[pos:], '`')
+	contentStart := pos + runLength
+	for searchPos := contentStart; searchPos < len(p.text); {
+		closeIdx := strings.IndexByte(p.text[searchPos:], '`')
+		if closeIdx < 0 {
+			break
+		}
+
+		closeStart := searchPos + closeIdx
+		closeLength := countRun(p.text[closeStart:], '`')
+		if closeLength == runLength {
+			// Line endings inside a code span are rendered as spaces
+			value := strings.ReplaceAll(p.text[contentStart:closeStart], "\n", " ")
+			if len(value) >= 2 && value[0] == ' ' && value[len(value)-1] == ' ' && strings.Trim(value, " ") != "" {
+				value = value[1 : len(value)-1]
+			}
+			p.addNode(&Code{Value: value})
+			return closeStart + closeLength
+		}
+		searchPos = closeStart + closeLength
+	}
+
+	// Without a matching closing run the backticks are literal text
+	p.plain.WriteString(p.text[pos:contentStart])
+	return contentStart
+}
+
+// parseDelimiterRun pushes a run of "*", "_", "~~" or "==" on the delimiter stack. Whether it can open
+// or close emphasis depends on the characters around it (left- and right-flanking rules).
+func (p *inlineParser) parseDelimiterRun(pos int) int {
+	char := p.text[pos]
+	end := pos + countRun(p.text[pos:], char)
+
+	before, after := '\n', '\n'
+	if pos > 0 {
+		before, _ = utf8.DecodeLastRuneInString(p.text[:pos])
+	}
+	if end < len(p.text) {
+		after, _ = utf8.DecodeRuneInString(p.text[end:])
+	}
+
+	leftFlanking := !unicode.IsSpace(after) && (!isPunctuation(after) || unicode.IsSpace(before) || isPunctuation(before))
+	rightFlanking := !unicode.IsSpace(before) && (!isPunctuation(before) || unicode.IsSpace(after) || isPunctuation(after))
+
+	run := &delimiter{node: &Text{Value: p.text[pos:end]}, char: char, count: end - pos, origCount: end - pos}
+	if char != '_' {
+		run.canOpen = leftFlanking
+		run.canClose = rightFlanking
+	} else {
+		// "_" inside a word, as in snake_case_name, is not emphasis
+		run.canOpen = leftFlanking && (!rightFlanking || isPunctuation(before))
+		run.canClose = rightFlanking && (!leftFlanking || isPunctuation(after))
+	}
+
+	p.addNode(run.node)
+	if run.canOpen || run.canClose {
+		p.delimiters = append(p.delimiters, run)
+	}
+
+	return end
+}
+
+func (p *inlineParser) addBracket(pos int, length int, isImage bool) int {
+	opener := &bracket{
+		node:            &Text{Value: p.text[pos : pos+length]},
+		isImage:         isImage,
+		active:          true,
+		delimitersBelow: len(p.delimiters) - 1,
+		labelStart:      pos + length,
+	}
+	p.addNode(opener.node)
+	p.brackets = append(p.brackets, opener)
+	return pos + length
+}
+
+// closeBracket tries to turn the text between the latest "[" and this "]" into a link or image
+func (p *inlineParser) closeBracket(pos int) int {
+	if len(p.brackets) == 0 {
+		p.plain.WriteByte(']')
+		return pos + 1
+	}
+
+	opener := p.brackets[len(p.brackets)-1]
+	p.brackets = p.brackets[:len(p.brackets)-1]
+	if !opener.active {
+		p.plain.WriteByte(']')
+		return pos + 1
+	}
+
+	destination, title, length, ok := p.parseLinkEnding(pos+1, p.text[opener.labelStart:pos])
+	if !ok {
+		p.plain.WriteByte(']')
+		return pos + 1
+	}
+
+	p.flushPlainText()
+	p.processEmphasis(opener.delimitersBelow)
+	openerIdx := p.indexOfNode(opener.node)
+	children := mergeTextNodes(append([]Inline{}, p.nodes[openerIdx+1:]...))
+	p.nodes = p.nodes[:openerIdx]
+
+	end := pos + 1 + length
+	attributes, attributesLength, hasAttributes := parseAttributeBlock(p.text[end:])
+	if hasAttributes {
+		end += attributesLength
+	}
+
+	if opener.isImage {
+		p.nodes = append(p.nodes, &Image{Source: destination, Alt: inlinePlainText(children), Title: title, Attributes: attributes})
+		return end
+	}
+
+	p.nodes = append(p.nodes, &Link{Destination: destination, Title: title, Children: unwrapLinks(children), Attributes: attributes})
+	// Links cannot contain other links, so earlier "[" openers are disabled
+	for _, earlier := range p.brackets {
+		if !earlier.isImage {
+			earlier.active = false
+		}
+	}
+
+	return end
+}
+
+// parseLinkEnding reads what follows "]": an inline "(destination "title")", a full
+// "[label]" or collapsed "[]" reference, or nothing for a shortcut reference
+func (p *inlineParser) parseLinkEnding(pos int, label string) (string, string, int, bool) {
+	rest := p.text[pos:]
+	if strings.HasPrefix(rest, "(") {
+		if destination, title, length, ok := parseLinkTarget(rest[1:]); ok {
+			return destination, title, length + 1, true
+		}
+	}
+
+	reference := label
+	length := 0
+	if strings.HasPrefix(rest, "[") {
+		referenceEnd := strings.IndexByte(rest, ']')
+		if referenceEnd < 0 {
+			return "", "", 0, false
+		}
+		if explicitReference := rest[1:referenceEnd]; explicitReference != "" {
+			reference = explicitReference
+		}
+		length = referenceEnd + 1
+	}
+
+	if strings.TrimSpace(reference) == "" {
+		return "", "", 0, false
+	}
+
+	definition := p.references.lookup(reference)
+	if definition == nil {
+		return "", "", 0, false
+	}
+
+	return definition.Destination, definition.Title, length, true
+}
+
+// processEmphasis matches openers and closers above the stack bottom and wraps the nodes
+// between them in Emphasis or Strong nodes
+func (p *inlineParser) processEmphasis(stackBottom int) {
+	for closerIdx := stackBottom + 1; closerIdx < len(p.delimiters); {
+		closer := p.delimiters[closerIdx]
+		if !closer.canClose {
+			closerIdx++
+			continue
+		}
+
+		openerIdx := p.findOpener(stackBottom, closerIdx)
+		if openerIdx < 0 {
+			if closer.canOpen {
+				closerIdx++
+			} else {
+				p.delimiters = append(p.delimiters[:closerIdx], p.delimiters[closerIdx+1:]...)
+			}
+			continue
+		}
+
+		opener := p.delimiters[openerIdx]
+		used := 1
+		if opener.count >= 2 && closer.count >= 2 {
+			used = 2
+		}
+		if opener.char == '~' || opener.char == '=' {
+			used = opener.count
+		}
+		opener.count -= used
+		closer.count -= used
+		opener.node.Value = opener.node.Value[:opener.count]
+		closer.node.Value = closer.node.Value[:closer.count]
+
+		start := p.indexOfNode(opener.node) + 1
+		end := p.indexOfNode(closer.node)
+		children := append([]Inline{}, p.nodes[start:end]...)
+		var emphasis Inline
+		switch {
+		case opener.char == '~':
+			emphasis = &Strikethrough{Children: children}
+		case opener.char == '=':
+			emphasis = &Highlight{Children: children}
+		case used == 2:
+			emphasis = &Strong{Children: children}
+		default:
+			emphasis = &Emphasis{Children: children}
+		}
+		p.nodes = append(p.nodes[:start], append([]Inline{emphasis}, p.nodes[end:]...)...)
+
+		// Delimiters inside the new emphasis can no longer match anything
+		p.delimiters = append(p.delimiters[:openerIdx+1], p.delimiters[closerIdx:]...)
+		closerIdx = openerIdx + 1
+
+		if opener.count == 0 {
+			p.removeNode(opener.node)
+			p.delimiters = append(p.delimiters[:openerIdx], p.delimiters[openerIdx+1:]...)
+			closerIdx--
+		}
+		if closer.count == 0 {
+			p.removeNode(closer.node)
+			p.delimiters = append(p.delimiters[:closerIdx], p.delimiters[closerIdx+1:]...)
+		}
+	}
+
+	if stackBottom+1 < len(p.delimiters) {
+		p.delimiters = p.delimiters[:stackBottom+1]
+	}
+}
+
+// findOpener returns the closest delimiter below the closer that can open its emphasis, or -1
+func (p *inlineParser) findOpener(stackBottom int, closerIdx int) int {
+	closer := p.delimiters[closerIdx]
+	for idx := closerIdx - 1; idx > stackBottom; idx-- {
+		opener := p.delimiters[idx]
+		if opener.char != closer.char || !opener.canOpen {
+			continue
+		}
+		if closer.char == '~' || closer.char == '=' {
+			return idx
+		}
+
+		// "Rule of 3": when one of the runs can both open and close, the runs do not match
+		// if their combined length is a multiple of 3, unless both lengths are
+		if (opener.canClose || closer.canOpen) &&
+			(opener.origCount+closer.origCount)%3 == 0 &&
+			(opener.origCount%3 != 0 || closer.origCount%3 != 0) {
+			continue
+		}
+
+		return idx
+	}
+
+	return -1
+}
+
+func (p *inlineParser) indexOfNode(node Inline) int {
+	for idx, candidate := range p.nodes {
+		if candidate == node {
+			return idx
+		}
+	}
+
+	return -1
+}
+
+func (p *inlineParser) removeNode(node Inline) {
+	if idx := p.indexOfNode(node); idx >= 0 {
+		p.nodes = append(p.nodes[:idx], p.nodes[idx+1:]...)
+	}
+}
+
+// mergeTextNodes joins neighbouring text nodes and drops empty ones
+func mergeTextNodes(nodes []Inline) []Inline {
+	var merged []Inline
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *Text:
+			if n.Value == "" {
+				continue
+			}
+			if last, ok := lastTextNode(merged); ok {
+				merged[len(merged)-1] = &Text{Value: last.Value + n.Value}
+				continue
+			}
+		case *Emphasis:
+			n.Children = mergeTextNodes(n.Children)
+		case *Strong:
+			n.Children = mergeTextNodes(n.Children)
+		case *Strikethrough:
+			n.Children = mergeTextNodes(n.Children)
+		case *Highlight:
+			n.Children = mergeTextNodes(n.Children)
+		}
+		merged = append(merged, node)
+	}
+
+	return merged
+}
+
+func lastTextNode(nodes []Inline) (*Text, bool) {
+	if len(nodes) == 0 {
+		return nil, false
+	}
+
+	text, ok := nodes[len(nodes)-1].(*Text)
+	return text, ok
+}
+
+// unwrapLinks replaces autolinks found inside link text with their plain text
+func unwrapLinks(nodes []Inline) []Inline {
+	for idx, node := range nodes {
+		switch n := node.(type) {
+		case *Link:
+			nodes[idx] = &Text{Value: inlinePlainText(n.Children)}
+		case *Emphasis:
+			n.Children = unwrapLinks(n.Children)
+		case *Strong:
+			n.Children = unwrapLinks(n.Children)
+		case *Strikethrough:
+			n.Children = unwrapLinks(n.Children)
+		case *Highlight:
+			n.Children = unwrapLinks(n.Children)
+		case *Subscript:
+			n.Children = unwrapLinks(n.Children)
+		case *Superscript:
+			n.Children = unwrapLinks(n.Children)
+		}
+	}
+
+	return mergeTextNodes(nodes)
+}
+
+// parseExtensionMarker handles "~", "=" and "^". Runs of exactly two "~" or "=" are
+// strikethrough and highlight delimiters; a single "~" or "^" opens a subscript or
+// superscript that ends at the next one, with no whitespace in between.
+func (p *inlineParser) parseExtensionMarker(pos int) int {
+	char := p.text[pos]
+	runLength := countRun(p.text[pos:], char)
+
+	switch {
+	case runLength == 2 && char == '~' && p.options.Strikethrough,
+		runLength == 2 && char == '=' && p.options.Highlight:
+		return p.parseDelimiterRun(pos)
+	case runLength == 1 && char == '~' && p.options.Subscript,
+		runLength == 1 && char == '^' && p.options.Superscript:
+		if length := p.parseScript(pos); length > 0 {
+			return pos + length
+		}
+	}
+
+	p.plain.WriteString(p.text[pos : pos+runLength])
+	return pos + runLength
+}
+
+func (p *inlineParser) parseScript(pos int) int {
+	char := p.text[pos]
+	end := strings.IndexByte(p.text[pos+1:], char)
+	if end <= 0 {
+		return 0
+	}
+
+	content := p.text[pos+1 : pos+1+end]
+	if strings.ContainsAny(content, " \t\n") || strings.HasPrefix(p.text[pos+1+end:], string([]byte{char, char})) {
+		return 0
+	}
+
+	children := parseInlines(content, p.references, p.options)
+	if char == '~' {
+		p.addNode(&Subscript{Children: children})
+	} else {
+		p.addNode(&Superscript{Children: children})
+	}
+	return end + 2
+}
+
+// parseKeyboard reads a "[[Ctrl+C]]" key combination starting at text[0:2] == "[["
+func parseKeyboard(text string) (Inline, int) {
+	end := strings.Index(text[2:], "]]")
+	if end <= 0 || strings.ContainsAny(text[2:2+end], "[]\n") {
+		return nil, 0
+	}
+
+	return &Keyboard{Value: strings.TrimSpace(text[2 : 2+end])}, end + 4
+}
+
+func countRun(text string, char byte) int {
+	length := 0
+	for length < len(text) && text[length] == char {
+		length++
+	}
+
+	return length
+}
+
+func isASCIIPunctuation(char byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", char) >= 0
+}
+
+func isPunctuation(char rune) bool {
+	return unicode.IsPunct(char) || unicode.IsSymbol(char)
+}
+
+// parseLinkTarget reads the part after "(" up to and including the closing ")".
+// The destination may hold balanced parentheses or be wrapped in <> to allow spaces.
+// A destination followed by a "title", 'title' or (title) gets the title split off.
+// Backslash escapes and character references are resolved in both.
+func parseLinkTarget(text string) (string, string, int, bool) {
+	rest := strings.TrimLeft(text, " \t\n")
+	if destination, afterDestination, ok := cutLinkDestination(rest); ok {
+		afterDestination = strings.TrimLeft(afterDestination, " \t\n")
+		if strings.HasPrefix(afterDestination, ")") {
+			return unescapeMarkdown(destination), "", len(text) - len(afterDestination) + 1, true
+		}
+
+		if title, titleLength, ok := parseLinkTitle(afterDestination); ok {
+			afterTitle := strings.TrimLeft(afterDestination[titleLength:], " \t\n")
+			if strings.HasPrefix(afterTitle, ")") {
+				return unescapeMarkdown(destination), unescapeMarkdown(title), len(text) - len(afterTitle) + 1, true
+			}
+		}
+	}
+
+	// Otherwise everything up to the closing parenthesis is the destination, spaces included
+	end := findLinkDestinationEnd(text, false)
+	if end <= 0 {
+		return "", "", 0, false
+	}
+
+	return unescapeMarkdown(text[:end]), "", end + 1, true
+}
+
+// cutLinkDestination splits a <destination> or a destination without spaces from the text after it
+func cutLinkDestination(text string) (string, string, bool) {
+	if strings.HasPrefix(text, "<") {
+		end := indexUnescaped(text[1:], "<>\n")
+		if end >= 0 && text[1+end] == '>' {
+			return text[1 : 1+end], text[end+2:], true
+		}
+	}
+
+	end := findLinkDestinationEnd(text, true)
+	if end < 0 {
+		return "", "", false
+	}
+	return text[:end], text[end:], true
+}
+
+// findLinkDestinationEnd returns the position of the ")" closing the link, skipping
+// balanced parentheses and escaped characters. With stopAtSpace the destination also
+// ends before whitespace. Returns -1 when the link is not closed.
+func findLinkDestinationEnd(text string, stopAtSpace bool) int {
+	depth := 0
+	for idx := 0; idx < len(text); idx++ {
+		switch text[idx] {
+		case '\\':
+			idx++
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return idx
+			}
+			depth--
+		case ' ', '\t', '\n':
+			if stopAtSpace {
+				return idx
+			}
+		}
+	}
+
+	return -1
+}
+
+func parseLinkTitle(text string) (string, int, bool) {
+	if text == "" {
+		return "", 0, false
+	}
+
+	closing := map[byte]byte{'"': '"', '\'': '\'', '(': ')'}[text[0]]
+	if closing == 0 {
+		return "", 0, false
+	}
+
+	end := indexUnescaped(text[1:], string(closing))
+	if end < 0 {
+		return "", 0, false
+	}
+
+	return text[1 : end+1], end + 2, true
+}
+
+// parseAutoLink reads a bare http(s) URL. Trailing punctuation and a closing parenthesis
+// without an opening one end the sentence, not the URL: "(see https://example.com)."
+func parseAutoLink(text string) (Inline, int) {
+	end := strings.IndexFunc(text, func(char rune) bool {
+		return char == ']' || char == '<' || char == '>' || unicode.IsSpace(char)
+	})
+	if end < 0 {
+		end = len(text)
+	}
+
+	url := trimAutoLinkEnd(text[:end])
+	if strings.HasSuffix(url, "://") {
+		return nil, 0
+	}
+
+	return &Link{Destination: url, Children: []Inline{&Text{Value: url}}}, len(url)
+}
+
+func trimAutoLinkEnd(url string) string {
+	for {
+		trimmed := strings.TrimRight(url, ".,:;!?")
+		if strings.HasSuffix(trimmed, ")") && strings.Count(trimmed, "(") < strings.Count(trimmed, ")") {
+			trimmed = trimmed[:len(trimmed)-1]
+		}
+		if trimmed == url {
+			return url
+		}
+		url = trimmed
+	}
+}
+
+// parseAngleAutoLink reads "<scheme:...>" as a link to the URI and "<user@example.com>" as a mailto link
+func parseAngleAutoLink(text string) (Inline, int) {
+	if match := uriAutoLinkPattern.FindStringSubmatch(text); match != nil {
+		return &Link{Destination: match[1], Children: []Inline{&Text{Value: match[1]}}}, len(match[0])
+	}
+	if match := emailAutoLinkPattern.FindStringSubmatch(text); match != nil {
+		return &Link{Destination: "mailto:" + match[1], Children: []Inline{&Text{Value: match[1]}}}, len(match[0])
+	}
+
+	return nil, 0
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	texttemplate "text/template"
+)
+
+// A template directory holds layouts/base.html with the page skeleton and its blocks,
+// one layouts/<name>.html per layout overriding the blocks, and partials/*.html
+const baseLayoutFile = "base.html"
+const defaultLayout = "default"
+
+var layoutNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// findTemplateFiles returns the base layout, the document layout and the partials pattern.
+// A missing default layout leaves the base layout alone, a missing named layout is an error.
+func findTemplateFiles(templatesDir string, layout string) (string, string, string, error) {
+	if layout == "" {
+		layout = defaultLayout
+	}
+	if !layoutNamePattern.MatchString(layout) {
+		return "", "", "", fmt.Errorf("invalid layout name %q", layout)
+	}
+
+	layoutsDir := filepath.Join(templatesDir, "layouts")
+	baseFile := filepath.Join(layoutsDir, baseLayoutFile)
+	if _, err := os.Stat(baseFile); err != nil {
+		return "", "", "", err
+	}
+
+	layoutFile := filepath.Join(layoutsDir, layout+".html")
+	if _, err := os.Stat(layoutFile); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", "", "", err
+		}
+		if layout != defaultLayout {
+			return "", "", "", fmt.Errorf("layout %q not found: %s does not exist", layout, layoutFile)
+		}
+		layoutFile = ""
+	}
+
+	partialsPattern := filepath.Join(templatesDir, "partials", "*.html")
+	if partials, err := filepath.Glob(partialsPattern); err != nil || len(partials) == 0 {
+		partialsPattern = ""
+	}
+
+	return baseFile, layoutFile, partialsPattern, nil
+}
+
+// parseLayoutTemplate parses the base layout, the partials and then the document layout,
+// so the blocks defined by the layout replace the ones of the base
+func parseLayoutTemplate(templatesDir string, layout string, options ConvertOptions, diagnostics *[]Diagnostic) (templateExecutor, error) {
+	baseFile, layoutFile, partialsPattern, err := findTemplateFiles(templatesDir, layout)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.TextTemplate {
+		textTemplate, err := texttemplate.New(baseLayoutFile).Funcs(templateFuncs(options, diagnostics)).ParseFiles(baseFile)
+		if err == nil && partialsPattern != "" {
+			textTemplate, err = textTemplate.ParseGlob(partialsPattern)
+		}
+		if err == nil && layoutFile != "" {
+			textTemplate, err = textTemplate.ParseFiles(layoutFile)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return textTemplate, nil
+	}
+
+	htmlTemplate, err := template.New(baseLayoutFile).Funcs(templateFuncs(options, diagnostics)).ParseFiles(baseFile)
+	if err == nil && partialsPattern != "" {
+		htmlTemplate, err = htmlTemplate.ParseGlob(partialsPattern)
+	}
+	if err == nil && layoutFile != "" {
+		htmlTemplate, err = htmlTemplate.ParseFiles(layoutFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return htmlTemplate, nil
+}
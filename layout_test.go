@@ -0,0 +1,120 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+// writeTemplateDir creates a template directory with the given files
+func writeTemplateDir(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		td.Require(t).CmpNoError(os.MkdirAll(filepath.Dir(path), 0755))
+		td.Require(t).CmpNoError(os.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+var layoutTestFiles = map[string]string{
+	"layouts/base.html": `<title>{{ .Title }}</title>{{ template "header.html" . }}` +
+		`<main>{{ block "main" . }}{{ .Content }}{{ end }}</main>{{ block "footer" . }}<footer>base</footer>{{ end }}`,
+	"layouts/default.html": `{{ define "main" }}<article>{{ .Content }}</article>{{ end }}`,
+	"layouts/post.html": `{{ define "main" }}<article class="post">{{ .Date | formatDate "2 January 2006" .Language }}{{ .Content }}</article>{{ end }}` +
+		`{{ define "footer" }}<footer>{{ .Author }}</footer>{{ end }}`,
+	"partials/header.html": `<header>{{ .Title | upper }}</header>`,
+}
+
+func TestConvertWithLayouts(t *testing.T) {
+	dir := writeTemplateDir(t, layoutTestFiles)
+
+	tests := []struct {
+		name     string
+		markdown string
+		expected string
+	}{
+		{
+			name:     "01 Default layout",
+			markdown: "---\ntitle: Notes & tips\n---\nText",
+			expected: "<title>Notes &amp; tips</title><header>NOTES &amp; TIPS</header><main><article><p>Text</p>\n</article></main><footer>base</footer>",
+		},
+		{
+			name:     "02 Layout selected in front matter",
+			markdown: "---\ntitle: Post\nlayout: post\nlanguage: pl\ndate: 2026-03-13\nauthor: Bogdan\n---\nText",
+			expected: "<title>Post</title><header>POST</header><main><article class=\"post\">13 marca 2026<p>Text</p>\n</article></main><footer>Bogdan</footer>",
+		},
+		{
+			name:     "03 Document without front matter",
+			markdown: "Text",
+			expected: "<title>Converted Document</title><header>CONVERTED DOCUMENT</header><main><article><p>Text</p>\n</article></main><footer>base</footer>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertMarkdownWithLayouts(tt.markdown, dir, "", ConvertOptions{})
+			td.CmpNoError(t, err)
+			td.Cmp(t, result.HTML, tt.expected)
+		})
+	}
+}
+
+func TestConvertWithBaseLayoutOnly(t *testing.T) {
+	dir := writeTemplateDir(t, map[string]string{
+		"layouts/base.html": `<main>{{ block "main" . }}{{ .Content }}{{ end }}</main>`,
+	})
+
+	result, err := ConvertMarkdownWithLayouts("# Title", dir, "", ConvertOptions{})
+
+	td.CmpNoError(t, err)
+	td.Cmp(t, result.HTML, "<main><h1 id=\"title\">Title</h1>\n</main>")
+}
+
+func TestConvertWithLayoutsTextTemplate(t *testing.T) {
+	dir := writeTemplateDir(t, layoutTestFiles)
+
+	result, err := ConvertMarkdownWithLayouts("---\ntitle: <b>Raw</b>\n---\nText", dir, "", ConvertOptions{TextTemplate: true})
+
+	td.CmpNoError(t, err)
+	td.Cmp(t, result.HTML, "<title><b>Raw</b></title><header><B>RAW</B></header><main><article><p>Text</p>\n</article></main><footer>base</footer>")
+}
+
+func TestConvertWithLayoutsErrors(t *testing.T) {
+	dir := writeTemplateDir(t, layoutTestFiles)
+
+	tests := []struct {
+		name     string
+		dir      string
+		markdown string
+		expected td.TestDeep
+	}{
+		{
+			name:     "01 Unknown layout",
+			dir:      dir,
+			markdown: "---\nlayout: gallery\n---\nText",
+			expected: td.String(`error parsing template: layout "gallery" not found: ` + filepath.Join(dir, "layouts", "gallery.html") + " does not exist"),
+		},
+		{
+			name:     "02 Layout name with a path",
+			dir:      dir,
+			markdown: "---\nlayout: ../secret\n---\nText",
+			expected: td.String(`error parsing template: invalid layout name "../secret"`),
+		},
+		{
+			name:     "03 Missing base layout",
+			dir:      t.TempDir(),
+			markdown: "Text",
+			expected: td.HasSuffix(filepath.Join("layouts", "base.html") + ": no such file or directory"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ConvertMarkdownWithLayouts(tt.markdown, tt.dir, "", ConvertOptions{})
+			td.Cmp(t, err, tt.expected)
+		})
+	}
+}
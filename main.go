@@ -14,28 +14,92 @@ func main() {
 	var inputFile = flag.String("input", "", "Input Markdown file (stdin if not specified)")
 	var outputFile = flag.String("output", "", "Output HTML file (stdout if not specified)")
 	var templateFile = flag.String("template", "", "HTML template file with %title% and %content% placeholders (optional)")
+	var templatesDir = flag.String("templates", "", "Template directory with layouts/base.html, layouts/<layout>.html and partials/*.html (optional)")
 	var title = flag.String("title", "", "Title for the HTML document (optional)")
 	var preview = flag.Bool("preview", false, "Open converted HTML in default browser")
+	var anchors = flag.Bool("anchors", false, "Add self-link anchors to headings")
+	defaults := DefaultConvertOptions()
+	var strikethrough = flag.Bool("strikethrough", defaults.Strikethrough, "Render ~~text~~ as <del>")
+	var highlight = flag.Bool("highlight", defaults.Highlight, "Render ==text== as <mark>")
+	var subscript = flag.Bool("subscript", defaults.Subscript, "Render H~2~O as <sub>")
+	var superscript = flag.Bool("superscript", defaults.Superscript, "Render x^2^ as <sup>")
+	var keyboard = flag.Bool("kbd", defaults.Keyboard, "Render [[Ctrl+C]] as <kbd>")
+	var safe = flag.Bool("safe", false, "Sanitize raw HTML, attributes and URLs against an allowlist; removed elements are reported on stderr")
+	var unsafe = flag.Bool("unsafe", false, "Pass raw HTML through to the output (default)")
+	var policyFile = flag.String("policy", "", "JSON sanitizer policy file used in safe mode (optional)")
+	var schemaFile = flag.String("schema", "", "JSON front matter schema file with required keys and value types (optional)")
+	var textTemplate = flag.Bool("text-template", false, "Execute the template with text/template, without escaping metadata")
 	flag.Parse()
 
 	if *help {
-		fmt.Println("Usage: md2html -input <markdown-file> [-output <html-file>] [-template <template-file>] [-title <title>] [-preview]")
+		fmt.Println("Usage: md2html -input <markdown-file> [-output <html-file>] [-template <template-file> | -templates <dir>] [-title <title>] [-anchors] [-safe [-policy <policy-file>]] [-schema <schema-file>] [-preview]")
 		fmt.Println("  -input     Input Markdown file (stdin if not specified)")
 		fmt.Println("  -output    Output HTML file (stdout if not specified)")
 		fmt.Println("  -template  HTML template file with {{.Title}} and {{.Content}} placeholders (optional)")
+		fmt.Println("  -templates Template directory: layouts/base.html defines blocks, layouts/<layout>.html overrides them")
+		fmt.Println("             and partials/*.html are loaded; the \"layout\" front matter key selects the layout (default)")
 		fmt.Println("  -title     Title for the HTML document")
+		fmt.Println("  -anchors   Add self-link anchors to headings")
+		fmt.Println("  -safe      Sanitize raw HTML, attributes and URLs against an allowlist; removed elements are reported on stderr")
+		fmt.Println("  -unsafe    Pass raw HTML through to the output (default)")
+		fmt.Println("  -policy    JSON sanitizer policy file with allowed tags, attributes and URL schemes (implies -safe)")
+		fmt.Println("  -schema    JSON front matter schema file; the conversion fails when the front matter does not match")
 		fmt.Println("  -preview   Open converted HTML in default browser")
+		fmt.Println("  -text-template  Execute the template with text/template (metadata is not escaped)")
+		fmt.Println("Inline extensions, all enabled by default (disable with e.g. -kbd=false):")
+		fmt.Println("  -strikethrough  Render ~~text~~ as <del>")
+		fmt.Println("  -highlight      Render ==text== as <mark>")
+		fmt.Println("  -subscript      Render H~2~O as <sub>")
+		fmt.Println("  -superscript    Render x^2^ as <sup>")
+		fmt.Println("  -kbd            Render [[Ctrl+C]] as <kbd>")
 		os.Exit(1)
 	}
 
-	err := ConvertMarkdown(*inputFile, *outputFile, *templateFile, *title, *preview)
+	if *templateFile != "" && *templatesDir != "" {
+		fmt.Println("Error: -template and -templates cannot be used together")
+		os.Exit(1)
+	}
+
+	if (*safe || *policyFile != "") && *unsafe {
+		fmt.Println("Error: -safe and -unsafe cannot be used together")
+		os.Exit(1)
+	}
+
+	options := ConvertOptions{
+		HeadingAnchors: *anchors,
+		Strikethrough:  *strikethrough,
+		Highlight:      *highlight,
+		Subscript:      *subscript,
+		Superscript:    *superscript,
+		Keyboard:       *keyboard,
+		SafeHTML:       *safe || *policyFile != "",
+		TextTemplate:   *textTemplate,
+	}
+	if *policyFile != "" {
+		policy, err := readSanitizerPolicy(*policyFile)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		options.HTMLPolicy = policy
+	}
+	if *schemaFile != "" {
+		schema, err := readFrontMatterSchema(*schemaFile)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		options.Schema = schema
+	}
+
+	err := ConvertMarkdown(*inputFile, *outputFile, *templateFile, *templatesDir, *title, *preview, options)
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-func ConvertMarkdown(inputFile, outputFile, templateFile, title string, preview bool) error {
+func ConvertMarkdown(inputFile, outputFile, templateFile, templatesDir, title string, preview bool, options ConvertOptions) error {
 	var content []byte
 	var err error
 	if inputFile == "" {
@@ -47,25 +111,35 @@ func ConvertMarkdown(inputFile, outputFile, templateFile, title string, preview
 		return fmt.Errorf("error reading input: %w", err)
 	}
 
-	// Read or assign default template
 	var html string
-	var templateContent string
-	if templateFile != "" {
-		text, err := os.ReadFile(templateFile)
-		if err != nil {
-			return fmt.Errorf("error reading template file: %w", err)
-		}
-		templateContent = string(text)
+	var result ConversionResult
+	if templatesDir != "" {
+		result, err = ConvertMarkdownWithLayouts(string(content), templatesDir, title, options)
 	} else {
-		templateContent = "<!DOCTYPE html>\n" +
-			"<html>\n<head>\n  <meta charset=\"UTF-8\">\n  <title>{{ .Title }}</title>\n</head>\n" +
-			"<body>{{ .Content }}</body>\n</html>"
-	}
+		// Read or assign default template
+		var templateContent string
+		if templateFile != "" {
+			text, err := os.ReadFile(templateFile)
+			if err != nil {
+				return fmt.Errorf("error reading template file: %w", err)
+			}
+			templateContent = string(text)
+		} else {
+			templateContent = "<!DOCTYPE html>\n" +
+				"<html>\n<head>\n  <meta charset=\"UTF-8\">\n  <title>{{ .Title }}</title>\n</head>\n" +
+				"<body>{{ .Content }}</body>\n</html>"
+		}
 
-	html, err = ConvertMarkdownToHTML(string(content), templateContent, title)
+		result, err = ConvertMarkdownDocument(string(content), templateContent, title, options)
+	}
 	if err != nil {
 		return err
 	}
+	html = result.HTML
+
+	for _, diagnostic := range result.Diagnostics {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", diagnostic)
+	}
 
 	if preview {
 		// Create temporary file
@@ -111,3 +185,21 @@ func ConvertMarkdown(inputFile, outputFile, templateFile, title string, preview
 
 	return nil
 }
+
+func readSanitizerPolicy(policyFile string) (*SanitizerPolicy, error) {
+	text, err := os.ReadFile(policyFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading policy file: %w", err)
+	}
+
+	return ParseSanitizerPolicy(text)
+}
+
+func readFrontMatterSchema(schemaFile string) (*FrontMatterSchema, error) {
+	text, err := os.ReadFile(schemaFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading schema file: %w", err)
+	}
+
+	return ParseFrontMatterSchema(text)
+}
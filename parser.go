@@ -0,0 +1,671 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var orderedListItemPattern = regexp.MustCompile(`^\d+\.\s(.*)`)
+var atxHeadingPattern = regexp.MustCompile(`^(#{1,6})(?:[ \t]+(.*))?$`)
+var atxClosingSequencePattern = regexp.MustCompile(`(?:^|[ \t]+)#+$`)
+var setextUnderlinePattern = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+var blockQuoteMarkerPattern = regexp.MustCompile(`^ {0,3}> ?`)
+var thematicBreakPattern = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+
+// parseMarkdown builds the document tree: block structure first, then inline content.
+// In safe mode the sanitizer rejects custom heading ids the policy does not allow.
+func parseMarkdown(markdown string, options ConvertOptions, sanitizer *htmlSanitizer) *Document {
+	lines := strings.Split(markdown, "\n")
+	doc := &Document{Children: parseBlocks(lines)}
+
+	references := collectLinkReferences(doc)
+	forEachInlineContent(doc.Children, func(content *InlineContent) {
+		content.Inlines = parseInlines(content.Raw, references, options)
+	})
+	assignHeadingIDs(doc, sanitizer)
+	resolveFootnotes(doc)
+
+	return doc
+}
+
+// footnoteIDPattern matches the ids of the footnotes section, "fn-1", "fnref-1" and "fnref-1-2"
+var footnoteIDPattern = regexp.MustCompile(`^fn(?:ref)?-\d+(?:-\d+)?$`)
+
+// assignHeadingIDs gives every heading a unique slug generated from its text.
+// Custom "{#id}" ids are reserved first, so a generated slug never takes them, and
+// a slug equal to a footnote id gets a "section-" prefix. A custom id rejected by the
+// sanitizer is dropped and the heading gets a generated slug instead.
+func assignHeadingIDs(doc *Document, sanitizer *htmlSanitizer) {
+	var headings []*Heading
+	walkBlocks(doc.Children, func(block Block) {
+		if heading, ok := block.(*Heading); ok {
+			if id := heading.Attributes.ID; id != "" && !sanitizer.allowCustomID(fmt.Sprintf("h%d", heading.Level), id) {
+				heading.Attributes.ID = ""
+			}
+			headings = append(headings, heading)
+		}
+	})
+
+	slugs := slugRegistry{}
+	for _, heading := range headings {
+		if heading.Attributes.ID != "" {
+			heading.ID = slugs.unique(heading.Attributes.ID)
+		}
+	}
+	for _, heading := range headings {
+		if heading.Attributes.ID == "" {
+			slug := slugify(inlinePlainText(heading.Content.Inlines))
+			if footnoteIDPattern.MatchString(slug) {
+				slug = defaultSlug + "-" + slug
+			}
+			heading.ID = slugs.unique(slug)
+		}
+	}
+}
+
+func parseBlocks(lines []string) []Block {
+	var blocks []Block
+
+	lineIdx := 0
+	for lineIdx < len(lines) {
+		currentLine := lines[lineIdx]
+
+		// Handle multiline code blocks (```)
+		if isCodeFenceLine(currentLine) {
+			newIdx, codeBlock := parseCodeBlock(lineIdx, lines)
+			blocks = append(blocks, codeBlock)
+			lineIdx = newIdx
+			continue
+		}
+
+		if getHTMLBlockKind(currentLine) > 0 {
+			newIdx, htmlBlock := parseHTMLBlock(lineIdx, lines)
+			blocks = append(blocks, htmlBlock)
+			lineIdx = newIdx
+			continue
+		}
+
+		// Check if this line starts a list block
+		if isListLine(strings.TrimSpace(currentLine)) {
+			newIdx, listBlock := collectListBlock(lineIdx, lines)
+			blocks = append(blocks, parseList(listBlock))
+			lineIdx = newIdx
+			continue
+		}
+
+		if isBlockQuoteLine(currentLine) {
+			newIdx, quote := parseQuoteBlock(lineIdx, lines)
+			blocks = append(blocks, quote)
+			lineIdx = newIdx
+			continue
+		}
+
+		if isTableStart(lines, lineIdx) {
+			newIdx, table := parseTable(lineIdx, lines)
+			blocks = append(blocks, table)
+			lineIdx = newIdx
+			continue
+		}
+
+		if isLinkReferenceDefinitionLine(currentLine) {
+			newIdx, definition := parseLinkReferenceDefinition(lineIdx, lines)
+			blocks = append(blocks, definition)
+			lineIdx = newIdx
+			continue
+		}
+
+		if isFootnoteDefinitionLine(currentLine) {
+			newIdx, definition := parseFootnoteDefinition(lineIdx, lines)
+			blocks = append(blocks, definition)
+			lineIdx = newIdx
+			continue
+		}
+
+		// Collapse consecutive empty lines into a single blank line
+		if isBlankLine(currentLine) {
+			lineIdx = skipBlankLines(lines, lineIdx+1)
+			blocks = append(blocks, &BlankLine{})
+			continue
+		}
+
+		if block := parseSingleLine(currentLine); block != nil {
+			blocks = append(blocks, block)
+			lineIdx++
+			continue
+		}
+
+		newIdx, paragraph := parseParagraph(lineIdx, lines)
+		blocks = append(blocks, paragraph)
+		lineIdx = newIdx
+	}
+
+	return blocks
+}
+
+func isBlankLine(ln string) bool {
+	return strings.TrimSpace(ln) == ""
+}
+
+// skipBlankLines returns the index of the first non-blank line at or after lineIdx
+func skipBlankLines(lines []string, lineIdx int) int {
+	for lineIdx < len(lines) && isBlankLine(lines[lineIdx]) {
+		lineIdx++
+	}
+	return lineIdx
+}
+
+// parseSingleLine recognizes blocks that always occupy exactly one line
+func parseSingleLine(line string) Block {
+	trimmed := strings.TrimSpace(line)
+
+	if strings.EqualFold(trimmed, tocMarker) {
+		return &TocMarker{}
+	}
+
+	if isThematicBreak(line) {
+		return &ThematicBreak{}
+	}
+
+	if heading := parseHeading(trimmed); heading != nil {
+		return heading
+	}
+
+	return nil
+}
+
+// startsNewBlock reports whether the line interrupts a paragraph
+func startsNewBlock(lines []string, lineIdx int) bool {
+	ln := lines[lineIdx]
+	return isCodeFenceLine(ln) ||
+		isHTMLBlockInterruption(ln) ||
+		isListLine(strings.TrimSpace(ln)) ||
+		isBlockQuoteLine(ln) ||
+		isTableStart(lines, lineIdx) ||
+		isFootnoteDefinitionLine(ln) ||
+		isLinkReferenceDefinitionLine(ln) ||
+		parseSingleLine(ln) != nil
+}
+
+// Consecutive non-blank lines form one paragraph. Leading indentation is dropped,
+// trailing spaces are kept until inline parsing, where they mark hard line breaks.
+// A paragraph followed by a "===" or "---" underline becomes a setext heading.
+func parseParagraph(lineIdx int, lines []string) (int, Block) {
+	paragraphLines := []string{strings.TrimLeft(lines[lineIdx], " \t")}
+
+	lineIdx++
+	for lineIdx < len(lines) && !isBlankLine(lines[lineIdx]) {
+		if level := getSetextHeadingLevel(lines[lineIdx]); level > 0 {
+			raw, attributes, _ := cutTrailingAttributeBlock(strings.TrimSpace(strings.Join(paragraphLines, "\n")))
+			return lineIdx + 1, &Heading{Level: level, Content: InlineContent{Raw: raw}, Attributes: attributes}
+		}
+
+		if startsNewBlock(lines, lineIdx) {
+			break
+		}
+
+		paragraphLines = append(paragraphLines, strings.TrimLeft(lines[lineIdx], " \t"))
+		lineIdx++
+	}
+
+	raw := strings.TrimRight(strings.Join(paragraphLines, "\n"), " \t")
+	raw, attributes, _ := cutTrailingAttributeBlock(raw)
+	return lineIdx, &Paragraph{Content: InlineContent{Raw: raw}, Attributes: attributes}
+}
+
+// parseHeading reads an ATX heading: one to six "#", a space and the text,
+// optionally followed by a closing sequence of "#"
+func parseHeading(trimmed string) *Heading {
+	match := atxHeadingPattern.FindStringSubmatch(trimmed)
+	if match == nil {
+		return nil
+	}
+
+	content := strings.TrimSpace(atxClosingSequencePattern.ReplaceAllString(match[2], ""))
+	content, attributes, _ := cutTrailingAttributeBlock(content)
+	return &Heading{Level: len(match[1]), Content: InlineContent{Raw: content}, Attributes: attributes}
+}
+
+// getSetextHeadingLevel returns 1 for a "===" underline, 2 for "---" and 0 for other lines
+func getSetextHeadingLevel(ln string) int {
+	match := setextUnderlinePattern.FindStringSubmatch(ln)
+	if match == nil {
+		return 0
+	}
+
+	if match[1][0] == '=' {
+		return 1
+	}
+	return 2
+}
+
+func isCodeFenceLine(ln string) bool {
+	trimmed := strings.TrimSpace(ln)
+	return strings.HasPrefix(trimmed, "```")
+}
+
+func getCodeFenceLanguage(ln string) string {
+	trimmed := strings.TrimSpace(ln)
+	if !strings.HasPrefix(trimmed, "```") {
+		return ""
+	}
+
+	return strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
+}
+
+// parseCodeFenceInfo splits "go {.numbered}" into the language and the attribute block
+func parseCodeFenceInfo(info string) (string, Attributes) {
+	if start := strings.IndexByte(info, '{'); start >= 0 {
+		if attributes, length, ok := parseAttributeBlock(info[start:]); ok && start+length == len(info) {
+			return unescapeMarkdown(strings.TrimSpace(info[:start])), attributes
+		}
+	}
+
+	return unescapeMarkdown(info), Attributes{}
+}
+
+func parseCodeBlock(lineIdx int, lines []string) (int, *CodeBlock) {
+	ln := lines[lineIdx]
+	depth := getLineDepth(ln)
+	codeBlock := &CodeBlock{}
+	codeBlock.Language, codeBlock.Attributes = parseCodeFenceInfo(getCodeFenceLanguage(ln))
+
+	lineIdx++
+	for lineIdx < len(lines) && !isCodeFenceLine(lines[lineIdx]) {
+		codeBlock.Lines = append(codeBlock.Lines, trimCodeLineIndentation(lines[lineIdx], depth))
+		lineIdx++
+	}
+
+	lineIdx++ // Skip closing ```
+	return lineIdx, codeBlock
+}
+
+func getLineDepth(ln string) int {
+	depth := 0
+	for _, char := range ln {
+		switch char {
+		case ' ':
+			depth++
+		case '\t':
+			depth += 4
+		default:
+			return depth
+		}
+	}
+	return depth
+}
+
+func trimCodeLineIndentation(line string, depth int) string {
+	if len(line) <= depth {
+		return ""
+	}
+
+	return line[depth:]
+}
+
+// isThematicBreak matches three or more "-", "*" or "_" characters, optionally separated by spaces
+func isThematicBreak(ln string) bool {
+	return thematicBreakPattern.MatchString(ln)
+}
+
+func isListLine(ln string) bool {
+	if isThematicBreak(ln) {
+		return false
+	}
+	if strings.HasPrefix(ln, "- ") {
+		return true
+	}
+	return orderedListItemPattern.MatchString(ln)
+}
+
+// collectListBlock gathers list items together with their blank lines, fenced code
+// and indented continuation lines
+func collectListBlock(lineIdx int, lines []string) (int, []string) {
+	listBlock := []string{}
+	insideCodeBlock := false
+	for lineIdx < len(lines) {
+		ln := lines[lineIdx]
+		trimmed := strings.TrimSpace(ln)
+		switch {
+		case isCodeFenceLine(trimmed):
+			insideCodeBlock = !insideCodeBlock
+		case insideCodeBlock, isListLine(trimmed), trimmed == "":
+		case isListContinuationLine(lines, lineIdx):
+		default:
+			return lineIdx, listBlock
+		}
+		listBlock = append(listBlock, ln)
+		lineIdx++
+	}
+
+	return lineIdx, listBlock
+}
+
+// An indented text line directly below a list item continues the item's text
+func isListContinuationLine(lines []string, lineIdx int) bool {
+	ln := lines[lineIdx]
+	if lineIdx == 0 || isBlankLine(ln) || isBlankLine(lines[lineIdx-1]) || getLineDepth(ln) == 0 {
+		return false
+	}
+
+	trimmed := strings.TrimSpace(ln)
+	return !isListLine(trimmed) && !isThematicBreak(trimmed) && !isCodeFenceLine(trimmed) && !isCodeFenceLine(lines[lineIdx-1])
+}
+
+func isOrderedListLine(trimmed string) bool {
+	return !strings.HasPrefix(trimmed, "- ")
+}
+
+func getListItemContent(trimmed string) string {
+	if after, ok := strings.CutPrefix(trimmed, "- "); ok {
+		return after
+	}
+
+	// Ordered list - remove number and dot
+	matches := orderedListItemPattern.FindStringSubmatch(trimmed)
+	if len(matches) > 1 {
+		return matches[1]
+	}
+
+	return ""
+}
+
+// Build the list tree from a block of list lines. Nesting follows the indentation
+// of consecutive items: a deeper item opens a sub-list inside the previous item,
+// a shallower one closes the innermost sub-list.
+func parseList(lines []string) *List {
+	root := &List{Ordered: isOrderedListLine(strings.TrimSpace(lines[0]))}
+	openLists := []*List{root}
+
+	lineIdx := 0
+	for lineIdx < len(lines) {
+		currentLine := lines[lineIdx]
+		trimmed := strings.TrimSpace(currentLine)
+		currentDepth := getLineDepth(currentLine)
+
+		if trimmed == "" {
+			lineIdx++
+			continue
+		}
+
+		list := openLists[len(openLists)-1]
+		itemLines := []string{getListItemContent(strings.TrimLeft(currentLine, " \t"))}
+		lineIdx++
+		for lineIdx < len(lines) && isListContinuationLine(lines, lineIdx) {
+			itemLines = append(itemLines, strings.TrimLeft(lines[lineIdx], " \t"))
+			lineIdx++
+		}
+		raw := strings.TrimRight(strings.Join(itemLines, "\n"), " \t")
+		item := parseTaskListItem(raw)
+		list.Items = append(list.Items, item)
+
+		// Skip empty lines
+		for lineIdx < len(lines) && strings.TrimSpace(lines[lineIdx]) == "" {
+			lineIdx++
+		}
+
+		// Look ahead to see if next item is deeper (for nested lists)
+		nextDepth := -1
+		nextIsCode := false
+		if lineIdx < len(lines) {
+			line := lines[lineIdx]
+			isNextListLine := isListLine(strings.TrimSpace(line))
+			nextIsCode = isCodeFenceLine(line)
+			nextDepth = getLineDepth(line)
+			if nextIsCode || !isNextListLine {
+				nextDepth = currentDepth
+			}
+		}
+
+		if nextIsCode {
+			newIdx, codeBlock := parseCodeBlock(lineIdx, lines)
+			item.Children = append(item.Children, codeBlock)
+			lineIdx = newIdx
+		}
+
+		if nextDepth >= 0 && nextDepth > currentDepth {
+			nested := &List{Ordered: isOrderedListLine(strings.TrimSpace(lines[lineIdx]))}
+			item.Children = append(item.Children, nested)
+			openLists = append(openLists, nested)
+		} else if nextDepth >= 0 && nextDepth < currentDepth && len(openLists) > 1 {
+			openLists = openLists[:len(openLists)-1]
+		}
+	}
+
+	return root
+}
+
+// parseTaskListItem creates a list item, recognizing the GFM "[ ]" and "[x]" task markers
+func parseTaskListItem(raw string) *ListItem {
+	for _, marker := range []string{"[ ]", "[x]", "[X]"} {
+		content, ok := strings.CutPrefix(raw, marker)
+		if !ok || (content != "" && content[0] != ' ' && content[0] != '\n') {
+			continue
+		}
+
+		return &ListItem{
+			Content: InlineContent{Raw: strings.TrimLeft(content, " \n")},
+			Task:    true,
+			Checked: marker != "[ ]",
+		}
+	}
+
+	return &ListItem{Content: InlineContent{Raw: raw}}
+}
+
+// isBlockQuoteLine accepts the same marker that stripBlockQuoteMarker removes:
+// up to three spaces of indentation and ">"
+func isBlockQuoteLine(ln string) bool {
+	return blockQuoteMarkerPattern.MatchString(ln)
+}
+
+// Consecutive quote lines form one block quote. Its content, with one level of ">"
+// markers removed, is parsed recursively, so ">>" gives a nested quote.
+// A quote opened with a registered "[!KIND]" marker becomes an admonition.
+func parseQuoteBlock(lineIdx int, lines []string) (int, Block) {
+	var contentLines []string
+	for lineIdx < len(lines) {
+		content, ok := stripBlockQuoteMarker(lines[lineIdx])
+		if !ok {
+			break
+		}
+		contentLines = append(contentLines, content)
+		lineIdx++
+	}
+
+	// without a marker the content would be the same line, parsed again forever
+	if len(contentLines) == 0 {
+		return lineIdx + 1, &Paragraph{Content: InlineContent{Raw: strings.TrimSpace(lines[lineIdx])}}
+	}
+
+	if kind, title, ok := parseAdmonitionMarker(contentLines[0]); ok {
+		return lineIdx, &Admonition{
+			Kind:     kind,
+			Title:    InlineContent{Raw: title},
+			Children: trimBlankLines(parseBlocks(contentLines[1:])),
+		}
+	}
+
+	quote := &BlockQuote{Children: trimBlankLines(parseBlocks(contentLines))}
+	extractBlockQuoteCallout(quote)
+	return lineIdx, quote
+}
+
+// stripBlockQuoteMarker removes the indentation, ">" and one following space
+func stripBlockQuoteMarker(ln string) (string, bool) {
+	marker := blockQuoteMarkerPattern.FindString(ln)
+	if marker == "" {
+		return ln, false
+	}
+	return ln[len(marker):], true
+}
+
+func trimBlankLines(blocks []Block) []Block {
+	for len(blocks) > 0 {
+		if _, ok := blocks[0].(*BlankLine); !ok {
+			break
+		}
+		blocks = blocks[1:]
+	}
+	for len(blocks) > 0 {
+		if _, ok := blocks[len(blocks)-1].(*BlankLine); !ok {
+			break
+		}
+		blocks = blocks[:len(blocks)-1]
+	}
+
+	return blocks
+}
+
+// The callout label is taken from the first paragraph of the quote
+func extractBlockQuoteCallout(quote *BlockQuote) {
+	if len(quote.Children) == 0 {
+		return
+	}
+
+	paragraph, ok := quote.Children[0].(*Paragraph)
+	if !ok {
+		return
+	}
+
+	label, rest, isCallout := splitBlockQuoteCallout(paragraph.Content.Raw)
+	if !isCallout {
+		return
+	}
+
+	quote.Callout = &InlineContent{Raw: label}
+	paragraph.Content.Raw = strings.TrimSpace(rest)
+	if paragraph.Content.Raw == "" {
+		quote.Children = quote.Children[1:]
+	}
+}
+
+func splitBlockQuoteCallout(content string) (string, string, bool) {
+	colonIdx := findBlockQuoteCalloutBoundary(content)
+	if colonIdx < 0 {
+		return "", "", false
+	}
+
+	label := content[:colonIdx+1]
+	return label, content[colonIdx+1:], true
+}
+
+func findBlockQuoteCalloutBoundary(content string) int {
+	colonIdx := strings.Index(content, ":")
+	if colonIdx < 0 {
+		return -1
+	}
+
+	if strings.ContainsAny(content[:colonIdx], ".,;-\n") {
+		return -1
+	}
+
+	return colonIdx
+}
+
+var tableDelimiterCellPattern = regexp.MustCompile(`^:?-+:?$`)
+
+// A table starts with a header row followed by a delimiter row with the same number of cells
+func isTableStart(lines []string, lineIdx int) bool {
+	if lineIdx+1 >= len(lines) || !strings.Contains(lines[lineIdx], "|") {
+		return false
+	}
+
+	alignments, ok := parseTableDelimiterRow(lines[lineIdx+1])
+	return ok && len(alignments) == len(splitTableRow(lines[lineIdx]))
+}
+
+func parseTable(lineIdx int, lines []string) (int, *Table) {
+	alignments, _ := parseTableDelimiterRow(lines[lineIdx+1])
+	table := &Table{
+		Alignments: alignments,
+		Header:     buildTableRow(splitTableRow(lines[lineIdx]), len(alignments)),
+	}
+
+	lineIdx += 2
+	for lineIdx < len(lines) && isTableRowLine(lines[lineIdx]) {
+		table.Rows = append(table.Rows, buildTableRow(splitTableRow(lines[lineIdx]), len(alignments)))
+		lineIdx++
+	}
+
+	return lineIdx, table
+}
+
+func isTableRowLine(ln string) bool {
+	trimmed := strings.TrimSpace(ln)
+	return trimmed != "" && strings.Contains(trimmed, "|")
+}
+
+func parseTableDelimiterRow(ln string) ([]string, bool) {
+	if !strings.Contains(ln, "-") {
+		return nil, false
+	}
+
+	cells := splitTableRow(ln)
+	alignments := make([]string, len(cells))
+	for idx, cell := range cells {
+		if !tableDelimiterCellPattern.MatchString(cell) {
+			return nil, false
+		}
+		alignments[idx] = getTableColumnAlignment(cell)
+	}
+
+	return alignments, true
+}
+
+func getTableColumnAlignment(delimiter string) string {
+	left := strings.HasPrefix(delimiter, ":")
+	right := strings.HasSuffix(delimiter, ":")
+	switch {
+	case left && right:
+		return "center"
+	case right:
+		return "right"
+	case left:
+		return "left"
+	}
+
+	return ""
+}
+
+// Missing cells are filled with empty ones, cells beyond the header width are dropped
+func buildTableRow(cells []string, columns int) *TableRow {
+	row := &TableRow{}
+	for idx := 0; idx < columns; idx++ {
+		cell := ""
+		if idx < len(cells) {
+			cell = cells[idx]
+		}
+		row.Cells = append(row.Cells, &TableCell{Content: InlineContent{Raw: cell}})
+	}
+
+	return row
+}
+
+// splitTableRow splits a row on unescaped pipes; the optional outer pipes are dropped
+// and "\|" is turned into a literal pipe inside the cell
+func splitTableRow(ln string) []string {
+	trimmed := strings.TrimSpace(ln)
+	trimmed = strings.TrimPrefix(trimmed, "|")
+	if strings.HasSuffix(trimmed, "|") && !strings.HasSuffix(trimmed, `\|`) {
+		trimmed = strings.TrimSuffix(trimmed, "|")
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for idx := 0; idx < len(trimmed); idx++ {
+		switch {
+		case trimmed[idx] == '\\' && idx+1 < len(trimmed) && trimmed[idx+1] == '|':
+			cell.WriteByte('|')
+			idx++
+		case trimmed[idx] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(trimmed[idx])
+		}
+	}
+	cells = append(cells, strings.TrimSpace(cell.String()))
+
+	return cells
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+const htmlTagName = `[A-Za-z][A-Za-z0-9-]*`
+const htmlAttribute = `\s+[A-Za-z_:][A-Za-z0-9_.:-]*(?:\s*=\s*(?:[^"'=<>` + "`" + `\s]+|'[^']*'|"[^"]*"))?`
+const htmlOpenTag = `<` + htmlTagName + `(?:` + htmlAttribute + `)*\s*/?>`
+const htmlClosingTag = `</` + htmlTagName + `\s*>`
+
+// inlineHTMLPattern matches a tag, comment, processing instruction, declaration or CDATA section
+var inlineHTMLPattern = regexp.MustCompile(`^(?:` + htmlOpenTag + `|` + htmlClosingTag +
+	`|<!-->|<!--->|<!--(?s:.*?)-->|<\?(?s:.*?)\?>|<![A-Za-z][^>]*>|<!\[CDATA\[(?s:.*?)\]\]>)`)
+
+const htmlBlockTagNames = `address|article|aside|base|basefont|blockquote|body|caption|center|col|colgroup|dd|` +
+	`details|dialog|dir|div|dl|dt|fieldset|figcaption|figure|footer|form|frame|frameset|h[1-6]|head|header|hr|` +
+	`html|iframe|legend|li|link|main|menu|menuitem|nav|noframes|ol|optgroup|option|p|param|search|section|` +
+	`summary|table|tbody|td|tfoot|th|thead|title|tr|track|ul`
+
+// htmlBlockKinds are the CommonMark HTML block types 1-7, tried in this order.
+// A block ends on the line matching its end pattern, or before a blank line when end is nil.
+var htmlBlockKinds = []struct {
+	start *regexp.Regexp
+	end   *regexp.Regexp
+}{
+	{regexp.MustCompile(`(?i)^ {0,3}<(?:script|pre|style|textarea)(?:[ \t>]|$)`), regexp.MustCompile(`(?i)</(?:script|pre|style|textarea)>`)},
+	{regexp.MustCompile(`^ {0,3}<!--`), regexp.MustCompile(`-->`)},
+	{regexp.MustCompile(`^ {0,3}<\?`), regexp.MustCompile(`\?>`)},
+	{regexp.MustCompile(`^ {0,3}<![A-Za-z]`), regexp.MustCompile(`>`)},
+	{regexp.MustCompile(`^ {0,3}<!\[CDATA\[`), regexp.MustCompile(`\]\]>`)},
+	{regexp.MustCompile(`(?i)^ {0,3}</?(?:` + htmlBlockTagNames + `)(?:[ \t>]|/>|$)`), nil},
+	{regexp.MustCompile(`^ {0,3}(?:` + htmlOpenTag + `|` + htmlClosingTag + `)[ \t]*$`), nil},
+}
+
+// lastInterruptingHTMLBlockKind is the last type that may interrupt a paragraph
+const lastInterruptingHTMLBlockKind = 6
+
+// getHTMLBlockKind returns the HTML block type (1-7) started by the line or 0
+func getHTMLBlockKind(ln string) int {
+	for idx, kind := range htmlBlockKinds {
+		if kind.start.MatchString(ln) {
+			return idx + 1
+		}
+	}
+
+	return 0
+}
+
+// isHTMLBlockInterruption reports whether the line starts an HTML block that may interrupt a paragraph
+func isHTMLBlockInterruption(ln string) bool {
+	kind := getHTMLBlockKind(ln)
+	return kind > 0 && kind <= lastInterruptingHTMLBlockKind
+}
+
+// parseHTMLBlock collects the lines of a raw HTML block; they are kept unchanged
+func parseHTMLBlock(lineIdx int, lines []string) (int, *HTMLBlock) {
+	end := htmlBlockKinds[getHTMLBlockKind(lines[lineIdx])-1].end
+
+	var blockLines []string
+	for lineIdx < len(lines) {
+		ln := lines[lineIdx]
+		if end == nil && isBlankLine(ln) {
+			break
+		}
+
+		blockLines = append(blockLines, ln)
+		lineIdx++
+		if end != nil && end.MatchString(ln) {
+			break
+		}
+	}
+
+	return lineIdx, &HTMLBlock{Literal: strings.Join(blockLines, "\n")}
+}
+
+// parseInlineHTML reads a raw HTML tag or comment at the start of text
+func parseInlineHTML(text string) (Inline, int) {
+	literal := inlineHTMLPattern.FindString(text)
+	if literal == "" {
+		return nil, 0
+	}
+
+	return &RawHTML{Literal: literal}, len(literal)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+var linkReferenceDefinitionPattern = regexp.MustCompile(`^ {0,3}\[([^\]^][^\]]*)\]:[ \t]*(<[^>]*>|\S+)(?:[ \t]+("[^"]*"|'[^']*'|\([^)]*\)))?[ \t]*$`)
+
+// linkReferences maps normalized labels to their definitions
+type linkReferences map[string]*LinkReferenceDefinition
+
+func isLinkReferenceDefinitionLine(ln string) bool {
+	return linkReferenceDefinitionPattern.MatchString(ln)
+}
+
+// parseLinkReferenceDefinition reads a `[label]: url "title"` line. The title may also be
+// quoted with ” or (), and the url may be wrapped in <> to allow spaces.
+func parseLinkReferenceDefinition(lineIdx int, lines []string) (int, *LinkReferenceDefinition) {
+	match := linkReferenceDefinitionPattern.FindStringSubmatch(lines[lineIdx])
+	definition := &LinkReferenceDefinition{
+		Label:       match[1],
+		Destination: unescapeMarkdown(strings.TrimSuffix(strings.TrimPrefix(match[2], "<"), ">")),
+	}
+	if title := match[3]; len(title) >= 2 {
+		definition.Title = unescapeMarkdown(title[1 : len(title)-1])
+	}
+
+	// Like a footnote definition, the definition takes the blank lines after it
+	return skipBlankLines(lines, lineIdx+1), definition
+}
+
+// collectLinkReferences gathers the definitions of the whole document; the first one wins
+func collectLinkReferences(doc *Document) linkReferences {
+	references := linkReferences{}
+	walkBlocks(doc.Children, func(block Block) {
+		definition, ok := block.(*LinkReferenceDefinition)
+		if !ok {
+			return
+		}
+
+		label := normalizeReferenceLabel(definition.Label)
+		if references[label] == nil {
+			references[label] = definition
+		}
+	})
+
+	return references
+}
+
+func (references linkReferences) lookup(label string) *LinkReferenceDefinition {
+	return references[normalizeReferenceLabel(label)]
+}
+
+// Labels match case-insensitively, with runs of whitespace treated as one space
+func normalizeReferenceLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// htmlRenderer walks the document tree and writes HTML
+type htmlRenderer struct {
+	out             strings.Builder
+	language        string // document language, selects localized default titles
+	tableOfContents string // injected in place of the [[toc]] marker
+	options         ConvertOptions
+	sanitizer       *htmlSanitizer // nil when raw HTML is passed through unchanged
+}
+
+func renderHTML(doc *Document, data *TemplateData, options ConvertOptions, sanitizer *htmlSanitizer) string {
+	renderer := &htmlRenderer{language: data.Language, tableOfContents: string(data.TOC), options: options, sanitizer: sanitizer}
+	renderer.renderBlocks(doc.Children)
+	return renderer.out.String()
+}
+
+func createIndentation(steps int) string {
+	return strings.Repeat("    ", steps)
+}
+
+func (r *htmlRenderer) renderBlocks(blocks []Block) {
+	for _, block := range blocks {
+		r.sanitizer.closeRawText()
+		switch b := block.(type) {
+		case *BlankLine:
+			r.out.WriteString("\n")
+		case *CodeBlock:
+			r.renderCodeBlock(b, "")
+		case *List:
+			r.renderList(b, 0)
+		case *Table:
+			r.renderTable(b)
+		case *TocMarker:
+			r.out.WriteString(r.tableOfContents)
+		case *HTMLBlock:
+			if literal := r.sanitizer.sanitizeHTML(b.Literal); literal != "" {
+				r.out.WriteString(literal + "\n")
+			}
+		case *FootnoteDefinition:
+			// rendered in the footnotes section
+		case *LinkReferenceDefinition:
+			// used only to resolve reference links
+		default:
+			r.renderLeafBlock(block)
+			r.out.WriteString("\n")
+		}
+	}
+}
+
+func (r *htmlRenderer) renderLeafBlock(block Block) {
+	switch b := block.(type) {
+	case *Heading:
+		r.renderHeading(b)
+	case *Paragraph:
+		r.renderParagraph(b)
+	case *BlockQuote:
+		r.renderBlockQuote(b)
+	case *Admonition:
+		r.renderAdmonition(b)
+	case *ThematicBreak:
+		r.out.WriteString("<hr>")
+	}
+}
+
+func (r *htmlRenderer) renderHeading(heading *Heading) {
+	// The id is generated or was checked when assigned, the TOC and anchors link to it
+	attributes := heading.Attributes
+	attributes.ID = ""
+	attributes = r.sanitizer.filterAttributes(fmt.Sprintf("h%d", heading.Level), attributes)
+	attributes.ID = heading.ID
+	fmt.Fprintf(&r.out, "<h%d%s>", heading.Level, buildAttributes(attributes, ""))
+	if r.options.HeadingAnchors {
+		fmt.Fprintf(&r.out, "<a class=\"anchor\" href=\"#%s\" aria-hidden=\"true\">#</a>", escapeHTML(heading.ID))
+	}
+	r.renderInlines(heading.Content.Inlines)
+	fmt.Fprintf(&r.out, "</h%d>", heading.Level)
+}
+
+func (r *htmlRenderer) renderParagraph(paragraph *Paragraph) {
+	// A paragraph holding a single image is rendered without the <p> wrapper
+	if inlines := paragraph.Content.Inlines; len(inlines) == 1 && paragraph.Attributes.isEmpty() {
+		if image, ok := inlines[0].(*Image); ok {
+			r.renderStandaloneImage(image)
+			return
+		}
+	}
+
+	r.out.WriteString("<p" + r.buildAttributes("p", paragraph.Attributes, "") + ">")
+	r.renderInlines(paragraph.Content.Inlines)
+	r.out.WriteString("</p>")
+}
+
+func (r *htmlRenderer) renderStandaloneImage(image *Image) {
+	caption, isFigure := strings.CutPrefix(image.Alt, "figure:")
+	if !isFigure {
+		r.renderImage(image, image.Alt)
+		return
+	}
+
+	caption = strings.TrimSpace(caption)
+	r.out.WriteString("<figure>\n  ")
+	r.renderImage(image, caption)
+	fmt.Fprintf(&r.out, "\n  <figcaption>%s</figcaption>\n</figure>", escapeHTML(caption))
+}
+
+func (r *htmlRenderer) renderImage(image *Image, alt string) {
+	fmt.Fprintf(&r.out, "<img%s alt=\"%s\"%s%s>", r.buildURLAttribute("img", "src", image.Source), escapeHTML(alt), buildTitleAttribute(image.Title), r.buildAttributes("img", image.Attributes.withoutPairs("src", "alt", "title"), ""))
+}
+
+func buildTitleAttribute(title string) string {
+	if title != "" {
+		return fmt.Sprintf(" title=\"%s\"", escapeHTML(title))
+	}
+
+	return ""
+}
+
+// A quote holding a single paragraph keeps its text inline, without the <p> wrapper.
+// Any other content is rendered as nested blocks.
+func (r *htmlRenderer) renderBlockQuote(quote *BlockQuote) {
+	if isTightBlockQuote(quote) {
+		r.out.WriteString("<blockquote>")
+		r.renderBlockQuoteCallout(quote)
+		if len(quote.Children) > 0 {
+			r.renderInlines(quote.Children[0].(*Paragraph).Content.Inlines)
+		}
+		r.out.WriteString("</blockquote>")
+		return
+	}
+
+	r.out.WriteString("<blockquote>\n")
+	children := quote.Children
+	if quote.Callout != nil {
+		r.out.WriteString("<p>")
+		r.renderBlockQuoteCallout(quote)
+		if paragraph, ok := children[0].(*Paragraph); ok {
+			r.renderInlines(paragraph.Content.Inlines)
+			children = children[1:]
+		}
+		r.out.WriteString("</p>\n")
+	}
+	r.renderBlocks(children)
+	r.out.WriteString("</blockquote>")
+}
+
+func isTightBlockQuote(quote *BlockQuote) bool {
+	if len(quote.Children) == 0 {
+		return true
+	}
+	if len(quote.Children) > 1 {
+		return false
+	}
+
+	_, ok := quote.Children[0].(*Paragraph)
+	return ok
+}
+
+func (r *htmlRenderer) renderBlockQuoteCallout(quote *BlockQuote) {
+	if quote.Callout == nil {
+		return
+	}
+
+	r.out.WriteString("<strong>")
+	r.renderInlines(quote.Callout.Inlines)
+	r.out.WriteString("</strong>")
+	if len(quote.Children) > 0 {
+		if _, ok := quote.Children[0].(*Paragraph); ok {
+			r.out.WriteString(" ")
+		}
+	}
+}
+
+func (r *htmlRenderer) renderAdmonition(admonition *Admonition) {
+	fmt.Fprintf(&r.out, "<div class=\"admonition %s\">\n", escapeHTML(admonition.Kind))
+	r.out.WriteString("<p class=\"admonition-title\">")
+	if len(admonition.Title.Inlines) > 0 {
+		r.renderInlines(admonition.Title.Inlines)
+	} else {
+		r.out.WriteString(escapeHTML(getAdmonitionTitle(admonition.Kind, r.language)))
+	}
+	r.out.WriteString("</p>\n")
+	r.renderBlocks(admonition.Children)
+	r.out.WriteString("</div>")
+}
+
+func (r *htmlRenderer) renderCodeBlock(code *CodeBlock, indentation string) {
+	if len(code.Lines) == 0 {
+		return
+	}
+
+	r.out.WriteString(indentation + "<div" + r.buildAttributes("div", code.Attributes.withoutPairs("data-language"), "code") + buildDataLanguageAttribute(code.Language) + ">\n")
+	r.out.WriteString(indentation + "<pre><code>")
+	for idx, line := range code.Lines {
+		if idx > 0 {
+			r.out.WriteString("\n")
+		}
+		r.out.WriteString(escapeHTML(line))
+	}
+	r.out.WriteString("</code></pre>\n" + indentation + "</div>\n")
+}
+
+func buildDataLanguageAttribute(language string) string {
+	if language != "" {
+		return fmt.Sprintf(" data-language=\"%s\"", escapeHTML(language))
+	}
+
+	return ""
+}
+
+// Lists are indented by level: <ul>/<ol> at 0, 8, 16, ... and <li> at 4, 12, 20, ...
+func (r *htmlRenderer) renderList(list *List, level int) {
+	listType := "ul"
+	if list.Ordered {
+		listType = "ol"
+	}
+
+	blockIndent := createIndentation(2 * level)
+	lineIndent := createIndentation(2*level + 1)
+
+	fmt.Fprintf(&r.out, "%s<%s%s>\n", blockIndent, listType, buildTaskListClassAttribute(list))
+	for _, item := range list.Items {
+		if item.Task {
+			r.out.WriteString(lineIndent + "<li class=\"task-list-item\">")
+			r.renderTaskCheckbox(item.Checked)
+		} else {
+			r.out.WriteString(lineIndent + "<li>")
+		}
+		r.renderInlines(item.Content.Inlines)
+		if len(item.Children) > 0 {
+			for _, child := range item.Children {
+				r.out.WriteString("\n")
+				r.renderListItemChild(child, level+1)
+			}
+			r.out.WriteString(lineIndent)
+		}
+		r.out.WriteString("</li>\n")
+	}
+	fmt.Fprintf(&r.out, "%s</%s>\n", blockIndent, listType)
+}
+
+func buildTaskListClassAttribute(list *List) string {
+	for _, item := range list.Items {
+		if item.Task {
+			return " class=\"contains-task-list\""
+		}
+	}
+
+	return ""
+}
+
+func (r *htmlRenderer) renderTaskCheckbox(checked bool) {
+	if checked {
+		r.out.WriteString("<input type=\"checkbox\" disabled checked> ")
+	} else {
+		r.out.WriteString("<input type=\"checkbox\" disabled> ")
+	}
+}
+
+func (r *htmlRenderer) renderListItemChild(block Block, level int) {
+	switch b := block.(type) {
+	case *List:
+		r.renderList(b, level)
+	case *CodeBlock:
+		r.renderCodeBlock(b, createIndentation(2*level))
+	}
+}
+
+func (r *htmlRenderer) renderTable(table *Table) {
+	r.out.WriteString("<table>\n")
+	r.out.WriteString(createIndentation(1) + "<thead>\n")
+	r.renderTableRow(table.Header, "th", table.Alignments)
+	r.out.WriteString(createIndentation(1) + "</thead>\n")
+	if len(table.Rows) > 0 {
+		r.out.WriteString(createIndentation(1) + "<tbody>\n")
+		for _, row := range table.Rows {
+			r.renderTableRow(row, "td", table.Alignments)
+		}
+		r.out.WriteString(createIndentation(1) + "</tbody>\n")
+	}
+	r.out.WriteString("</table>\n")
+}
+
+func (r *htmlRenderer) renderTableRow(row *TableRow, cellTag string, alignments []string) {
+	r.out.WriteString(createIndentation(2) + "<tr>\n")
+	for idx, cell := range row.Cells {
+		fmt.Fprintf(&r.out, "%s<%s%s>", createIndentation(3), cellTag, buildAlignmentClassAttribute(alignments[idx]))
+		r.renderInlines(cell.Content.Inlines)
+		fmt.Fprintf(&r.out, "</%s>\n", cellTag)
+	}
+	r.out.WriteString(createIndentation(2) + "</tr>\n")
+}
+
+func buildAlignmentClassAttribute(alignment string) string {
+	if alignment != "" {
+		return fmt.Sprintf(" class=\"align-%s\"", alignment)
+	}
+
+	return ""
+}
+
+func (r *htmlRenderer) renderInlines(inlines []Inline) {
+	for _, inline := range inlines {
+		// the content of a removed <script> or <style> ends at raw HTML with its closing tag
+		if _, isRawHTML := inline.(*RawHTML); r.sanitizer.insideRawText() && !isRawHTML {
+			continue
+		}
+		r.renderInline(inline)
+	}
+}
+
+func (r *htmlRenderer) renderInline(inline Inline) {
+	switch n := inline.(type) {
+	case *Text:
+		r.out.WriteString(escapeHTML(n.Value))
+	case *SoftBreak:
+		r.out.WriteString("\n")
+	case *HardBreak:
+		r.out.WriteString("<br>\n")
+	case *Code:
+		r.out.WriteString("<code>" + escapeHTML(n.Value) + "</code>")
+	case *Strong:
+		r.out.WriteString("<strong>")
+		r.renderInlines(n.Children)
+		r.out.WriteString("</strong>")
+	case *Emphasis:
+		r.out.WriteString("<em>")
+		r.renderInlines(n.Children)
+		r.out.WriteString("</em>")
+	case *Strikethrough:
+		r.out.WriteString("<del>")
+		r.renderInlines(n.Children)
+		r.out.WriteString("</del>")
+	case *Highlight:
+		r.out.WriteString("<mark>")
+		r.renderInlines(n.Children)
+		r.out.WriteString("</mark>")
+	case *Subscript:
+		r.out.WriteString("<sub>")
+		r.renderInlines(n.Children)
+		r.out.WriteString("</sub>")
+	case *Superscript:
+		r.out.WriteString("<sup>")
+		r.renderInlines(n.Children)
+		r.out.WriteString("</sup>")
+	case *Entity:
+		r.out.WriteString(n.Literal)
+	case *RawHTML:
+		r.out.WriteString(r.sanitizer.sanitizeHTML(n.Literal))
+	case *Keyboard:
+		r.out.WriteString("<kbd>" + escapeHTML(n.Value) + "</kbd>")
+	case *Link:
+		fmt.Fprintf(&r.out, "<a%s%s%s>", r.buildURLAttribute("a", "href", n.Destination), buildTitleAttribute(n.Title), r.buildAttributes("a", n.Attributes.withoutPairs("href", "title"), ""))
+		r.renderInlines(n.Children)
+		r.out.WriteString("</a>")
+	case *Image:
+		r.renderImage(n, n.Alt)
+	case *FootnoteReference:
+		r.renderFootnoteReference(n)
+	case *FootnoteBackReference:
+		r.renderFootnoteBackReference(n)
+	}
+}
+
+// buildAttributes renders an attribute block of the tag, without the parts the sanitizer removes
+func (r *htmlRenderer) buildAttributes(tag string, attributes Attributes, baseClass string) string {
+	return buildAttributes(r.sanitizer.filterAttributes(tag, attributes), baseClass)
+}
+
+// buildURLAttribute renders an attribute holding a URL, or nothing when the sanitizer rejects it
+func (r *htmlRenderer) buildURLAttribute(tag string, name string, url string) string {
+	if !r.sanitizer.allowURL(tag, name, url) {
+		return ""
+	}
+	return fmt.Sprintf(" %s=\"%s\"", name, escapeHTML(url))
+}
+
+func escapeHTML(text string) string {
+	text = strings.ReplaceAll(text, "&", "&amp;")
+	text = strings.ReplaceAll(text, "<", "&lt;")
+	text = strings.ReplaceAll(text, ">", "&gt;")
+	text = strings.ReplaceAll(text, "\"", "&quot;")
+	text = strings.ReplaceAll(text, "'", "&#39;")
+	return text
+}
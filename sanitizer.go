@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var htmlTagPattern = regexp.MustCompile(`^<(/?)(` + htmlTagName + `)((?:` + htmlAttribute + `)*)\s*(/?)>$`)
+var htmlAttributePartPattern = regexp.MustCompile(`\s+([A-Za-z_:][A-Za-z0-9_.:-]*)(?:\s*=\s*([^"'=<>` + "`" + `\s]+|'[^']*'|"[^"]*"))?`)
+var urlSchemePattern = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9+.-]*):`)
+
+// urlAttributes hold URLs, their scheme is checked against the policy
+var urlAttributes = map[string]bool{
+	"href": true, "src": true, "cite": true, "action": true, "formaction": true,
+	"poster": true, "background": true, "longdesc": true, "xlink:href": true,
+}
+
+// Removing one of these tags removes its content as well
+var rawTextTags = map[string]bool{
+	"script": true, "style": true, "textarea": true, "template": true, "iframe": true, "object": true,
+}
+
+// SanitizerPolicy is the allowlist applied in safe mode to raw HTML, attribute blocks and URLs
+type SanitizerPolicy struct {
+	Tags             map[string][]string `json:"tags"`             // allowed tags with the attributes allowed on them
+	GlobalAttributes []string            `json:"globalAttributes"` // attributes allowed on every allowed tag
+	URLSchemes       []string            `json:"urlSchemes"`       // relative URLs are always allowed
+}
+
+// Formatting tags allowed by the default policy with the global attributes only
+var defaultSanitizerTags = []string{
+	"abbr", "b", "br", "caption", "cite", "code", "dd", "del", "dfn", "div", "dl", "dt", "em",
+	"figcaption", "figure", "h1", "h2", "h3", "h4", "h5", "h6", "hr", "i", "ins", "kbd", "li",
+	"mark", "p", "pre", "s", "samp", "section", "small", "span", "strong", "sub", "summary", "sup",
+	"table", "tbody", "tfoot", "thead", "tr", "u", "ul", "var",
+}
+
+// DefaultSanitizerPolicy allows formatting tags and http, https and mailto URLs
+func DefaultSanitizerPolicy() *SanitizerPolicy {
+	policy := &SanitizerPolicy{
+		Tags: map[string][]string{
+			"a":          {"href"},
+			"img":        {"src", "alt", "width", "height"},
+			"blockquote": {"cite"},
+			"q":          {"cite"},
+			"ol":         {"start", "reversed"},
+			"td":         {"align", "colspan", "rowspan"},
+			"th":         {"align", "colspan", "rowspan", "scope"},
+			"details":    {"open"},
+		},
+		GlobalAttributes: []string{"id", "class", "title", "lang", "dir"},
+		URLSchemes:       []string{"http", "https", "mailto"},
+	}
+	for _, tag := range defaultSanitizerTags {
+		policy.Tags[tag] = []string{}
+	}
+
+	return policy
+}
+
+// ParseSanitizerPolicy reads a policy from JSON, e.g.
+// {"tags": {"a": ["href"], "p": []}, "globalAttributes": ["class"], "urlSchemes": ["https"]}
+func ParseSanitizerPolicy(data []byte) (*SanitizerPolicy, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	policy := &SanitizerPolicy{}
+	if err := decoder.Decode(policy); err != nil {
+		return nil, fmt.Errorf("error parsing sanitizer policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// Diagnostic reports a tag or attribute removed by the sanitizer
+type Diagnostic struct {
+	Element   string // "<iframe>", or "comment", "declaration", ... for other markup
+	Attribute string // empty when the whole element was removed
+	Reason    string
+}
+
+func (d Diagnostic) String() string {
+	if d.Attribute != "" {
+		return fmt.Sprintf("removed %s attribute from %s: %s", d.Attribute, d.Element, d.Reason)
+	}
+
+	return fmt.Sprintf("removed %s: %s", d.Element, d.Reason)
+}
+
+// htmlSanitizer applies a policy and collects diagnostics. A nil sanitizer (unsafe mode)
+// lets everything through.
+type htmlSanitizer struct {
+	tags             map[string]map[string]bool
+	globalAttributes map[string]bool
+	urlSchemes       map[string]bool
+	diagnostics      []Diagnostic
+	rawTextTag       string // removed tag, e.g. "script", whose closing tag was not reached yet
+}
+
+// newHTMLSanitizer returns nil unless options select safe mode
+func newHTMLSanitizer(options ConvertOptions) *htmlSanitizer {
+	if !options.SafeHTML {
+		return nil
+	}
+
+	policy := options.HTMLPolicy
+	if policy == nil {
+		policy = DefaultSanitizerPolicy()
+	}
+
+	sanitizer := &htmlSanitizer{
+		tags:             map[string]map[string]bool{},
+		globalAttributes: toLowerSet(policy.GlobalAttributes),
+		urlSchemes:       toLowerSet(policy.URLSchemes),
+	}
+	for tag, attributes := range policy.Tags {
+		sanitizer.tags[strings.ToLower(tag)] = toLowerSet(attributes)
+	}
+
+	return sanitizer
+}
+
+func toLowerSet(values []string) map[string]bool {
+	set := map[string]bool{}
+	for _, value := range values {
+		set[strings.ToLower(value)] = true
+	}
+	return set
+}
+
+func (s *htmlSanitizer) report(diagnostic Diagnostic) {
+	s.diagnostics = append(s.diagnostics, diagnostic)
+}
+
+// reportedDiagnostics returns the diagnostics collected so far
+func (s *htmlSanitizer) reportedDiagnostics() []Diagnostic {
+	if s == nil {
+		return nil
+	}
+	return s.diagnostics
+}
+
+// allowAttribute checks the attribute of an allowed tag and reports it when removed
+func (s *htmlSanitizer) allowAttribute(tag string, name string, value string) bool {
+	element := "<" + tag + ">"
+	if !s.tags[tag][name] && !s.globalAttributes[name] {
+		s.report(Diagnostic{Element: element, Attribute: name, Reason: "attribute is not allowed"})
+		return false
+	}
+
+	if urlAttributes[name] {
+		if scheme := getURLScheme(value); scheme != "" && !s.urlSchemes[scheme] {
+			s.report(Diagnostic{Element: element, Attribute: name, Reason: fmt.Sprintf("URL scheme %q is not allowed", scheme)})
+			return false
+		}
+	}
+
+	return true
+}
+
+// getURLScheme returns the lower case scheme of the URL, or "" for a relative URL.
+// Whitespace and control characters are ignored, as browsers do.
+func getURLScheme(url string) string {
+	cleaned := strings.Map(func(char rune) rune {
+		if char <= ' ' {
+			return -1
+		}
+		return char
+	}, html.UnescapeString(url))
+
+	match := urlSchemePattern.FindStringSubmatch(cleaned)
+	if match == nil {
+		return ""
+	}
+	return strings.ToLower(match[1])
+}
+
+// allowURL checks a URL written by markdown syntax, e.g. the destination of a link
+func (s *htmlSanitizer) allowURL(tag string, name string, url string) bool {
+	return s == nil || s.allowAttribute(tag, name, url)
+}
+
+// allowCustomID checks an id written in an attribute block, e.g. "# Title {#intro}"
+func (s *htmlSanitizer) allowCustomID(tag string, id string) bool {
+	return s == nil || s.allowAttribute(tag, "id", id)
+}
+
+// filterAttributes drops the parts of an attribute block the policy does not allow
+func (s *htmlSanitizer) filterAttributes(tag string, attributes Attributes) Attributes {
+	if s == nil || attributes.isEmpty() {
+		return attributes
+	}
+
+	filtered := Attributes{}
+	if attributes.ID != "" && s.allowAttribute(tag, "id", attributes.ID) {
+		filtered.ID = attributes.ID
+	}
+	if len(attributes.Classes) > 0 && s.allowAttribute(tag, "class", "") {
+		filtered.Classes = attributes.Classes
+	}
+	for _, pair := range attributes.Pairs {
+		if s.allowAttribute(tag, strings.ToLower(pair.Key), pair.Value) {
+			filtered.Pairs = append(filtered.Pairs, pair)
+		}
+	}
+
+	return filtered
+}
+
+// sanitizeHTML rewrites raw HTML keeping only allowed tags and attributes.
+// Text between the tags is kept, a "<" that does not start a tag is escaped.
+func (s *htmlSanitizer) sanitizeHTML(literal string) string {
+	if s == nil {
+		return literal
+	}
+
+	pos := 0
+	if s.rawTextTag != "" {
+		length, closed := skipRawTextContent(literal, s.rawTextTag)
+		if !closed {
+			return ""
+		}
+		pos, s.rawTextTag = length, ""
+	}
+
+	var out strings.Builder
+	for pos < len(literal) {
+		next := strings.IndexByte(literal[pos:], '<')
+		if next < 0 {
+			out.WriteString(literal[pos:])
+			break
+		}
+		out.WriteString(literal[pos : pos+next])
+		pos += next
+
+		token := inlineHTMLPattern.FindString(literal[pos:])
+		if token == "" {
+			out.WriteString("&lt;")
+			pos++
+			continue
+		}
+		pos += len(token)
+
+		match := htmlTagPattern.FindStringSubmatch(token)
+		if match == nil {
+			s.report(Diagnostic{Element: describeHTMLMarkup(token), Reason: "only tags are allowed"})
+			continue
+		}
+
+		closing, name, attributes, selfClosing := match[1] == "/", strings.ToLower(match[2]), match[3], match[4] == "/"
+		if _, ok := s.tags[name]; !ok {
+			if !closing {
+				s.report(Diagnostic{Element: "<" + name + ">", Reason: "tag is not allowed"})
+				if rawTextTags[name] && !selfClosing {
+					length, closed := skipRawTextContent(literal[pos:], name)
+					if !closed {
+						s.rawTextTag = name
+					}
+					pos += length
+				}
+			}
+			continue
+		}
+
+		if closing {
+			out.WriteString("</" + name + ">")
+		} else {
+			out.WriteString(s.sanitizeTag(name, attributes, selfClosing))
+		}
+	}
+
+	return out.String()
+}
+
+func (s *htmlSanitizer) sanitizeTag(name string, attributes string, selfClosing bool) string {
+	var tag strings.Builder
+	tag.WriteString("<" + name)
+	for _, attribute := range htmlAttributePartPattern.FindAllStringSubmatch(attributes, -1) {
+		key, value := strings.ToLower(attribute[1]), attribute[2]
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') {
+			value = value[1 : len(value)-1]
+		}
+		value = html.UnescapeString(value)
+
+		if !s.allowAttribute(name, key, value) {
+			continue
+		}
+		if attribute[2] == "" {
+			tag.WriteString(" " + key)
+		} else {
+			fmt.Fprintf(&tag, " %s=\"%s\"", key, escapeHTML(value))
+		}
+	}
+	if selfClosing {
+		tag.WriteString(" /")
+	}
+	tag.WriteString(">")
+
+	return tag.String()
+}
+
+// skipRawTextContent returns the length of the text up to and including the closing tag
+// and whether the closing tag was found
+func skipRawTextContent(text string, name string) (int, bool) {
+	start := strings.Index(strings.ToLower(text), "</"+name)
+	if start < 0 {
+		return len(text), false
+	}
+
+	end := strings.IndexByte(text[start:], '>')
+	if end < 0 {
+		return len(text), false
+	}
+	return start + end + 1, true
+}
+
+// insideRawText reports whether the content of a removed tag like <script> is being skipped.
+// Inline raw HTML splits "<script>alert(1)</script>" into tags and text, the text is dropped
+// until the closing tag.
+func (s *htmlSanitizer) insideRawText() bool {
+	return s != nil && s.rawTextTag != ""
+}
+
+// closeRawText ends skipping at the end of a block, an unclosed tag drops the rest of its block only
+func (s *htmlSanitizer) closeRawText() {
+	if s != nil {
+		s.rawTextTag = ""
+	}
+}
+
+func describeHTMLMarkup(token string) string {
+	switch {
+	case strings.HasPrefix(token, "<!--"):
+		return "comment"
+	case strings.HasPrefix(token, "<![CDATA["):
+		return "CDATA section"
+	case strings.HasPrefix(token, "<?"):
+		return "processing instruction"
+	default:
+		return "declaration"
+	}
+}
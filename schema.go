@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// FrontMatterSchema declares the front matter keys a document must or may have, e.g.
+// {"keys": {"date": {"type": "date", "required": true}, "status": {"type": "enum", "values": ["draft", "published"]}}}
+type FrontMatterSchema struct {
+	Keys map[string]SchemaKey `json:"keys"`
+}
+
+// SchemaKey is the rule for one front matter key
+type SchemaKey struct {
+	Type     string   `json:"type"`     // string, date, enum, list, number or boolean; empty for any value
+	Required bool     `json:"required"` // the key must be present and not empty
+	Values   []string `json:"values"`   // allowed values of an enum, string or list items
+}
+
+var schemaKeyTypes = []string{"", "string", "date", "enum", "list", "number", "boolean"}
+
+// ParseFrontMatterSchema reads a schema from JSON and checks its key rules
+func ParseFrontMatterSchema(data []byte) (*FrontMatterSchema, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	schema := &FrontMatterSchema{}
+	if err := decoder.Decode(schema); err != nil {
+		return nil, fmt.Errorf("error parsing front matter schema: %w", err)
+	}
+
+	for _, key := range schema.sortedKeys() {
+		rule := schema.Keys[key]
+		if !slices.Contains(schemaKeyTypes, rule.Type) {
+			return nil, fmt.Errorf("error parsing front matter schema: key %q has unknown type %q", key, rule.Type)
+		}
+		if rule.Type == "enum" && len(rule.Values) == 0 {
+			return nil, fmt.Errorf("error parsing front matter schema: enum key %q has no values", key)
+		}
+	}
+
+	return schema, nil
+}
+
+func (s *FrontMatterSchema) sortedKeys() []string {
+	keys := make([]string, 0, len(s.Keys))
+	for key := range s.Keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SchemaViolation is a front matter key that does not match its rule
+type SchemaViolation struct {
+	Key     string
+	Message string
+}
+
+// schemaError lists every violation, one key per line
+type schemaError struct {
+	Violations []SchemaViolation
+}
+
+func (e *schemaError) Error() string {
+	lines := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		lines = append(lines, fmt.Sprintf("  - %s: %s", violation.Key, violation.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// validate checks the front matter against every key rule; keys without a rule are allowed
+func (s *FrontMatterSchema) validate(meta map[string]any) error {
+	var violations []SchemaViolation
+	for _, key := range s.sortedKeys() {
+		if message := s.Keys[key].check(meta[key]); message != "" {
+			violations = append(violations, SchemaViolation{Key: key, Message: message})
+		}
+	}
+
+	if len(violations) > 0 {
+		return &schemaError{Violations: violations}
+	}
+	return nil
+}
+
+// check returns what is wrong with the value, or "" when it matches the rule
+func (rule SchemaKey) check(value any) string {
+	if value == nil || value == "" {
+		if rule.Required {
+			return "required key is missing"
+		}
+		return ""
+	}
+
+	switch rule.Type {
+	case "string", "enum":
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Sprintf("expected a string, got %s", describeFrontMatterValue(value))
+		}
+		return rule.checkAllowed(text)
+	case "date":
+		text, ok := value.(string)
+		if _, valid := parseFrontMatterDate(text); !ok || !valid {
+			return fmt.Sprintf("%s is not a valid date, expected YYYY-MM-DD", formatFrontMatterValue(value))
+		}
+	case "list":
+		items, ok := value.([]any)
+		if !ok {
+			return fmt.Sprintf("expected a list, got %s", describeFrontMatterValue(value))
+		}
+		for _, item := range items {
+			text, ok := frontMatterText(item)
+			if !ok {
+				return fmt.Sprintf("list item %s is not a scalar", formatFrontMatterValue(item))
+			}
+			if message := rule.checkAllowed(text); message != "" {
+				return "list item " + message
+			}
+		}
+	case "number":
+		switch value.(type) {
+		case int, float64:
+		default:
+			return fmt.Sprintf("expected a number, got %s", describeFrontMatterValue(value))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Sprintf("expected a boolean, got %s", describeFrontMatterValue(value))
+		}
+	}
+
+	return ""
+}
+
+func (rule SchemaKey) checkAllowed(text string) string {
+	if len(rule.Values) == 0 || slices.Contains(rule.Values, text) {
+		return ""
+	}
+	return fmt.Sprintf("%q is not one of %s", text, strings.Join(rule.Values, ", "))
+}
+
+func describeFrontMatterValue(value any) string {
+	switch value.(type) {
+	case string:
+		return "a string"
+	case int, float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	case []any:
+		return "a list"
+	case map[string]any:
+		return "a mapping"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func formatFrontMatterValue(value any) string {
+	if text, ok := value.(string); ok {
+		return fmt.Sprintf("%q", text)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const defaultSlug = "section"
+
+// transliterations maps accented Latin letters to their ASCII spelling
+var transliterations = map[rune]string{
+	'ą': "a", 'ć': "c", 'ę': "e", 'ł': "l", 'ń': "n", 'ó': "o", 'ś': "s", 'ź': "z", 'ż': "z",
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'æ': "ae", 'ā': "a", 'ă': "a",
+	'ç': "c", 'č': "c", 'ĉ': "c", 'ċ': "c", 'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ė': "e", 'ě': "e",
+	'ğ': "g", 'ģ': "g", 'ĥ': "h", 'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'ı': "i",
+	'ķ': "k", 'ĺ': "l", 'ľ': "l", 'ļ': "l", 'ñ': "n", 'ň': "n", 'ņ': "n",
+	'ò': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o", 'œ': "oe",
+	'ŕ': "r", 'ř': "r", 'š': "s", 'ş': "s", 'ß': "ss", 'ť': "t", 'ţ': "t", 'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u",
+	'ý': "y", 'ÿ': "y", 'ž': "z",
+}
+
+// slugify turns text into a lowercase, hyphen separated identifier:
+// "Fragmentacja Intencji" becomes "fragmentacja-intencji"
+func slugify(text string) string {
+	var slug strings.Builder
+	pendingSeparator := false
+
+	for _, char := range strings.ToLower(text) {
+		if ascii, ok := transliterations[char]; ok {
+			writeSlugPart(&slug, ascii, &pendingSeparator)
+			continue
+		}
+
+		switch {
+		case unicode.IsLetter(char) || unicode.IsDigit(char):
+			writeSlugPart(&slug, string(char), &pendingSeparator)
+		case unicode.IsSpace(char) || char == '-' || char == '_':
+			pendingSeparator = slug.Len() > 0
+		}
+	}
+
+	if slug.Len() == 0 {
+		return defaultSlug
+	}
+
+	return slug.String()
+}
+
+func writeSlugPart(slug *strings.Builder, part string, pendingSeparator *bool) {
+	if *pendingSeparator {
+		slug.WriteByte('-')
+		*pendingSeparator = false
+	}
+	slug.WriteString(part)
+}
+
+// slugRegistry hands out unique slugs; repeated ones get a "-2", "-3", ... suffix.
+// Every slug handed out maps to the next suffix to try, so repeats do not rescan the taken ones.
+type slugRegistry map[string]int
+
+func (registry slugRegistry) unique(slug string) string {
+	next, taken := registry[slug]
+	if !taken {
+		registry[slug] = 2
+		return slug
+	}
+
+	for {
+		candidate := slug + "-" + strconv.Itoa(next)
+		next++
+		if _, taken := registry[candidate]; !taken {
+			registry[slug] = next
+			registry[candidate] = 2
+			return candidate
+		}
+	}
+}
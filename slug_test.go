@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{name: "01 Lowercase words joined with hyphens", text: "Main Title", expected: "main-title"},
+		{name: "02 Polish letters are transliterated", text: "Fragmentacja Intencji", expected: "fragmentacja-intencji"},
+		{name: "03 Polish diacritics", text: "Zażółć gęślą jaźń", expected: "zazolc-gesla-jazn"},
+		{name: "04 Punctuation is dropped", text: "What's new? (v2.0)", expected: "whats-new-v20"},
+		{name: "05 Separators collapse", text: "Level four - Nested  subsection", expected: "level-four-nested-subsection"},
+		{name: "06 Leading and trailing separators are trimmed", text: "  -- Intro --  ", expected: "intro"},
+		{name: "07 Other Latin letters", text: "Straße Ærø Čech", expected: "strasse-aero-cech"},
+		{name: "08 Non-Latin letters are kept", text: "Привет мир", expected: "привет-мир"},
+		{name: "09 Text without letters falls back to default", text: "!!!", expected: "section"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, slugify(tt.text), tt.expected)
+		})
+	}
+}
+
+func TestSlugRegistryAddsSuffixToDuplicates(t *testing.T) {
+	slugs := slugRegistry{}
+
+	td.Cmp(t, []string{
+		slugs.unique("intro"),
+		slugs.unique("intro"),
+		slugs.unique("intro-2"),
+		slugs.unique("intro"),
+	}, []string{"intro", "intro-2", "intro-2-2", "intro-3"})
+}
+
+func TestSlugRegistryWithManyDuplicates(t *testing.T) {
+	slugs := slugRegistry{}
+	slugs.unique("a-3")
+
+	var last string
+	for i := 0; i < 100000; i++ {
+		last = slugs.unique("a")
+	}
+
+	td.Cmp(t, last, "a-100001")
+	td.Cmp(t, len(slugs), 100001)
+}
@@ -0,0 +1,59 @@
+package main
+
+import "strings"
+
+// defaultReadingSpeed is the words per minute of a language without a measured speed
+const defaultReadingSpeed = 200
+
+// readingSpeeds are average silent reading speeds in words per minute; languages with
+// longer words are read at fewer words per minute
+var readingSpeeds = map[string]int{
+	"en": 228, "es": 218, "nl": 202, "sv": 199, "fr": 195, "it": 188, "ru": 184,
+	"pt": 181, "de": 179, "pl": 166, "tr": 166, "fi": 161,
+}
+
+// readingMinutes returns the minutes needed to read the words, at least one
+func readingMinutes(words int, language string) int {
+	speed, ok := readingSpeeds[normalizeLanguageCode(language)]
+	if !ok {
+		speed = defaultReadingSpeed
+	}
+
+	return max(1, (words+speed-1)/speed)
+}
+
+// collectDocumentStatistics fills the counters, the excerpt and the first image of data.
+// Only rendered content is counted, without unreferenced footnotes; code blocks, code spans
+// and image descriptions are not counted as words.
+func collectDocumentStatistics(doc *Document, data *TemplateData) {
+	forEachRenderedInlineContent(doc, func(content *InlineContent) {
+		data.WordCount += len(strings.Fields(inlineProseText(content.Inlines)))
+		walkInlines(content.Inlines, func(inline Inline) {
+			if image, ok := inline.(*Image); ok {
+				if data.ImageCount == 0 {
+					data.FirstImage = image.Source
+				}
+				data.ImageCount++
+			}
+		})
+	})
+	data.ReadingMinutes = readingMinutes(data.WordCount, data.Language)
+
+	walkBlocks(doc.Children, func(block Block) {
+		switch block.(type) {
+		case *Heading:
+			data.HeadingCount++
+		case *CodeBlock:
+			data.CodeBlockCount++
+		}
+	})
+
+	// The excerpt is the first top-level paragraph, not one of a quote, list or footnote.
+	// A paragraph with an image alone is not an excerpt.
+	for _, block := range doc.Children {
+		if paragraph, ok := block.(*Paragraph); ok && strings.TrimSpace(inlineProseText(paragraph.Content.Inlines)) != "" {
+			data.Excerpt = strings.Join(strings.Fields(inlinePlainText(paragraph.Content.Inlines)), " ")
+			break
+		}
+	}
+}
@@ -142,6 +142,9 @@ func (c *Context) WhenIRunTheCommand(command string) error {
 	// Write mock files to temp directory
 	for filename, content := range c.Files {
 		filePath := filepath.Join(tempDir, filename)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", filename, err)
+		}
 		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
 			return fmt.Errorf("failed to write file %s: %w", filename, err)
 		}
@@ -256,6 +259,14 @@ func (c *Context) ThenTheHtmlOutputShouldContain(expected string) error {
 	return c.ThenIShouldGetHtmlOutputContaining(expected)
 }
 
+func (c *Context) ThenTheErrorOutputShouldContain(expected string) error {
+	expected = normalizeStepText(expected)
+	if !strings.Contains(c.CommandError, expected) {
+		return fmt.Errorf("expected error output to contain '%s', but got: %s", expected, c.CommandError)
+	}
+	return nil
+}
+
 func (c *Context) ThenIShouldSeeHelpTextContaining(expected string) error {
 	expected = normalizeStepText(expected)
 	output := c.CommandOutput + c.CommandError
@@ -272,6 +283,15 @@ func (c *Context) ThenIShouldGetAnErrorMessage() error {
 	return nil
 }
 
+func (c *Context) ThenIShouldGetAnErrorMessageContaining(expected string) error {
+	expected = normalizeStepText(expected)
+	errorMsg := c.CommandError + c.CommandOutput
+	if c.ExitCode == 0 || !strings.Contains(errorMsg, expected) {
+		return fmt.Errorf("expected an error message containing '%s', but got: %q", expected, errorMsg)
+	}
+	return nil
+}
+
 func (c *Context) ThenTheCommandShouldExitWithCode1() error {
 	if c.ExitCode != 1 {
 		return fmt.Errorf("expected exit code 1, but got %d. Output: %s, Error: %s", c.ExitCode, c.CommandOutput, c.CommandError)
@@ -350,7 +370,7 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 
 	// Register Then steps
 	ctx.Then(`^I should get HTML content:$`, scenarioContext.ThenIShouldGetHtmlContent)
-	ctx.Then(`^I should get HTML containing "([^"]*)"$`, scenarioContext.ThenIShouldGetHtmlContaining)
+	ctx.Then(`^I should get HTML containing "(.*)"$`, scenarioContext.ThenIShouldGetHtmlContaining)
 
 	// Register CLI testing steps
 	ctx.Given(`^I have a markdown file "([^"]*)" with content "([^"]*)"$`, scenarioContext.GivenIHaveAMarkdownFileWithContent)
@@ -364,8 +384,10 @@ func InitializeScenario(ctx *godog.ScenarioContext) {
 	ctx.Then(`^I should get HTML output containing "(.*)"$`, scenarioContext.ThenIShouldGetHtmlOutputContaining)
 	ctx.Then(`^the HTML output should contain a title "([^"]*)"$`, scenarioContext.ThenTheHtmlOutputShouldContainATitle)
 	ctx.Then(`^the HTML output should contain "(.*)"$`, scenarioContext.ThenTheHtmlOutputShouldContain)
+	ctx.Then(`^the error output should contain "(.*)"$`, scenarioContext.ThenTheErrorOutputShouldContain)
 	ctx.Then(`^I should see help text containing "(.*)"$`, scenarioContext.ThenIShouldSeeHelpTextContaining)
 	ctx.Then(`^I should get an error message$`, scenarioContext.ThenIShouldGetAnErrorMessage)
+	ctx.Then(`^I should get an error message containing "(.*)"$`, scenarioContext.ThenIShouldGetAnErrorMessageContaining)
 	ctx.Then(`^the command should exit with code 1$`, scenarioContext.ThenTheCommandShouldExitWithCode1)
 	ctx.Then(`^I should get an error message about template parsing$`, scenarioContext.ThenIShouldGetAnErrorMessageAboutTemplateParsing)
 }
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var htmlTagsPattern = regexp.MustCompile(`<[^>]*>`)
+
+// htmlCodePattern matches code blocks and code spans, which are not counted as words
+var htmlCodePattern = regexp.MustCompile(`(?is)<pre[\s>].*?</pre>|<code[\s>].*?</code>`)
+
+// dateNames are the month and weekday names of a language, in time.Month and time.Weekday order
+type dateNames struct {
+	months         [12]string
+	monthsGenitive [12]string // used after the day number: "13 marca 2026"
+	shortMonths    [12]string
+	weekdays       [7]string
+	shortWeekdays  [7]string
+}
+
+// dateLocales lists the languages with their own names; English uses the names of the time package
+var dateLocales = map[string]dateNames{
+	"pl": {
+		months: [12]string{"styczeń", "luty", "marzec", "kwiecień", "maj", "czerwiec",
+			"lipiec", "sierpień", "wrzesień", "październik", "listopad", "grudzień"},
+		monthsGenitive: [12]string{"stycznia", "lutego", "marca", "kwietnia", "maja", "czerwca",
+			"lipca", "sierpnia", "września", "października", "listopada", "grudnia"},
+		shortMonths:   [12]string{"sty", "lut", "mar", "kwi", "maj", "cze", "lip", "sie", "wrz", "paź", "lis", "gru"},
+		weekdays:      [7]string{"niedziela", "poniedziałek", "wtorek", "środa", "czwartek", "piątek", "sobota"},
+		shortWeekdays: [7]string{"niedz.", "pon.", "wt.", "śr.", "czw.", "pt.", "sob."},
+	},
+}
+
+// dateNameTokens are the layout elements replaced by localized names, longest first
+var dateNameTokens = []string{"January", "Monday", "Jan", "Mon"}
+
+// templateFuncs are the functions available in document templates. Markdown rendered
+// by markdownify follows the conversion options, in safe mode it is sanitized and the
+// removed elements are added to diagnostics.
+func templateFuncs(options ConvertOptions, diagnostics *[]Diagnostic) map[string]any {
+	return map[string]any{
+		"formatDate":  formatDate,
+		"slugify":     func(text any) string { return slugify(templateText(text)) },
+		"truncate":    truncateText,
+		"markdownify": func(text any) template.HTML { return markdownify(templateText(text), options, diagnostics) },
+		"upper":       func(text any) string { return strings.ToUpper(templateText(text)) },
+		"lower":       func(text any) string { return strings.ToLower(templateText(text)) },
+		"default":     defaultValue,
+		"join":        joinValues,
+		"readingTime": readingTime,
+		"safeHTML":    func(text any) template.HTML { return template.HTML(templateText(text)) },
+	}
+}
+
+// templateText formats any template value, e.g. template.HTML, as text
+func templateText(value any) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+// formatDate formats the date with a Go layout using the month and weekday names of the
+// language: {{ .Date | formatDate "2 January 2006" "pl" }} gives "13 marca 2026".
+// A date that cannot be parsed is returned as written.
+func formatDate(layout string, language string, date any) string {
+	var parsed time.Time
+	switch value := date.(type) {
+	case time.Time:
+		parsed = value
+	default:
+		text := templateText(date)
+		var ok bool
+		if parsed, ok = parseFrontMatterDate(text); !ok {
+			return text
+		}
+	}
+
+	names, ok := dateLocales[normalizeLanguageCode(language)]
+	if !ok {
+		return parsed.Format(layout)
+	}
+	return formatLocalizedDate(parsed, layout, names)
+}
+
+// formatLocalizedDate formats the layout between the name tokens with the time package
+func formatLocalizedDate(date time.Time, layout string, names dateNames) string {
+	var out strings.Builder
+	for {
+		token, idx := findDateNameToken(layout)
+		if idx < 0 {
+			out.WriteString(date.Format(layout))
+			return out.String()
+		}
+
+		out.WriteString(date.Format(layout[:idx]))
+
+		month, weekday := date.Month()-1, date.Weekday()
+		switch token {
+		case "January":
+			if followsDayNumber(layout[:idx]) {
+				out.WriteString(names.monthsGenitive[month])
+			} else {
+				out.WriteString(names.months[month])
+			}
+		case "Jan":
+			out.WriteString(names.shortMonths[month])
+		case "Monday":
+			out.WriteString(names.weekdays[weekday])
+		case "Mon":
+			out.WriteString(names.shortWeekdays[weekday])
+		}
+		layout = layout[idx+len(token):]
+	}
+}
+
+// findDateNameToken returns the first month or weekday name in the layout and its position
+func findDateNameToken(layout string) (string, int) {
+	first, firstIdx := "", -1
+	for _, token := range dateNameTokens {
+		idx := strings.Index(layout, token)
+		if idx >= 0 && (firstIdx < 0 || idx < firstIdx) {
+			first, firstIdx = token, idx
+		}
+	}
+	return first, firstIdx
+}
+
+// followsDayNumber reports whether the layout before a month name ends with the day: "2 " or "02."
+func followsDayNumber(layout string) bool {
+	trimmed := strings.TrimRight(layout, " .")
+	return strings.HasSuffix(trimmed, "2") && !strings.HasSuffix(trimmed, "2006")
+}
+
+// truncateText shortens text to at most length characters, cutting at a word boundary
+// when possible and adding an ellipsis
+func truncateText(length int, text any) string {
+	runes := []rune(templateText(text))
+	if len(runes) <= length {
+		return string(runes)
+	}
+
+	cut := string(runes[:length])
+	if !unicode.IsSpace(runes[length]) {
+		if idx := strings.LastIndexFunc(cut, unicode.IsSpace); idx > 0 {
+			cut = cut[:idx]
+		}
+	}
+
+	return strings.TrimRightFunc(cut, func(char rune) bool {
+		return unicode.IsSpace(char) || unicode.IsPunct(char)
+	}) + "…"
+}
+
+// markdownify converts markdown text to HTML; a single paragraph is returned without <p>
+func markdownify(text string, options ConvertOptions, diagnostics *[]Diagnostic) template.HTML {
+	data := TemplateData{}
+	*diagnostics = append(*diagnostics, generateHtmlBodyFromMarkdown(text, &data, options)...)
+
+	content := strings.TrimSuffix(string(data.Content), "\n")
+	if strings.HasPrefix(content, "<p>") && strings.HasSuffix(content, "</p>") && strings.Count(content, "<p>") == 1 {
+		return template.HTML(content[len("<p>") : len(content)-len("</p>")])
+	}
+	return template.HTML(content)
+}
+
+// defaultValue returns value, or fallback when value is empty: {{ .Author | default "Anonymous" }}
+func defaultValue(fallback any, value any) any {
+	if value == nil {
+		return fallback
+	}
+
+	reflected := reflect.ValueOf(value)
+	switch reflected.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String:
+		if reflected.Len() == 0 {
+			return fallback
+		}
+	default:
+		if reflected.IsZero() {
+			return fallback
+		}
+	}
+	return value
+}
+
+// joinValues joins the items of a list with the separator: {{ .Meta.tags | join ", " }}
+func joinValues(separator string, items any) string {
+	switch list := items.(type) {
+	case []string:
+		return strings.Join(list, separator)
+	case []any:
+		texts := make([]string, 0, len(list))
+		for _, item := range list {
+			texts = append(texts, templateText(item))
+		}
+		return strings.Join(texts, separator)
+	}
+	return templateText(items)
+}
+
+// readingTime returns the minutes needed to read the text or HTML at the reading speed
+// of the language, at least one: {{ readingTime .Content .Language }}. Code is not counted.
+func readingTime(content any, language string) int {
+	text := htmlCodePattern.ReplaceAllString(templateText(content), " ")
+	words := len(strings.Fields(htmlTagsPattern.ReplaceAllString(text, " ")))
+	return readingMinutes(words, language)
+}
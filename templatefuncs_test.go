@@ -0,0 +1,133 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestFormatDate(t *testing.T) {
+	tests := []struct {
+		name     string
+		layout   string
+		language string
+		date     any
+		expected string
+	}{
+		{name: "01 English", layout: "2 January 2006", language: "en", date: "2026-03-13", expected: "13 March 2026"},
+		{name: "02 Polish day and month", layout: "2 January 2006", language: "pl", date: "2026-03-13", expected: "13 marca 2026"},
+		{name: "03 Polish month alone", layout: "January 2006", language: "pl", date: "2026-03-13", expected: "marzec 2026"},
+		{name: "04 Polish weekday and short month", layout: "Monday, 02 Jan 2006", language: "pl-PL", date: "2026-03-13", expected: "piątek, 13 mar 2026"},
+		{name: "05 Short weekday", layout: "Mon 2.01.2006 15:04", language: "pl", date: "2026-05-01T08:30:00Z", expected: "pt. 1.05.2026 08:30"},
+		{name: "06 Unknown language uses English", layout: "January 2, 2006", language: "de", date: "2026-12-24", expected: "December 24, 2026"},
+		{name: "07 Date as text", layout: "2 January", language: "pl", date: "2026-07-04", expected: "4 lipca"},
+		{name: "08 Time value", layout: "2006/01/02", language: "", date: time.Date(2026, 3, 13, 0, 0, 0, 0, time.UTC), expected: "2026/03/13"},
+		{name: "09 Invalid date is kept as written", layout: "2 January 2006", language: "pl", date: "2026-13-45", expected: "2026-13-45"},
+		{name: "10 Language code with spaces", layout: "2 January 2006", language: " PL_pl ", date: "2026-03-13", expected: "13 marca 2026"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, formatDate(tt.layout, tt.language, tt.date), tt.expected)
+		})
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		name     string
+		length   int
+		text     string
+		expected string
+	}{
+		{name: "01 Short text is kept", length: 20, text: "Class helpers", expected: "Class helpers"},
+		{name: "02 Cut at word boundary", length: 12, text: "Czytelny kod dzięki Class Helpers", expected: "Czytelny kod…"},
+		{name: "03 Cut inside a word", length: 15, text: "Czytelny kod dzięki Class Helpers", expected: "Czytelny kod…"},
+		{name: "04 Trailing punctuation is dropped", length: 14, text: "Readable code, with helpers", expected: "Readable code…"},
+		{name: "05 Single long word", length: 4, text: "Fragmentacja", expected: "Frag…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, truncateText(tt.length, tt.text), tt.expected)
+		})
+	}
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	markdown := "---\ntitle: Czytelny kod dzięki Class Helpers\nlanguage: pl\ndate: 2026-03-13\n" +
+		"tags: [delphi, refactoring]\nintro: \"Use **class helpers** <button>now</button>\"\n---\nText"
+
+	tests := []struct {
+		name     string
+		template string
+		options  ConvertOptions
+		expected string
+	}{
+		{name: "01 Date prints as written", template: `{{ .Date }} {{ .ParsedDate.Year }}`, expected: "2026-03-13 2026"},
+		{name: "02 formatDate", template: `{{ .Date | formatDate "2 January 2006" .Language }}`, expected: "13 marca 2026"},
+		{name: "03 slugify", template: `{{ .Title | slugify }}`, expected: "czytelny-kod-dzieki-class-helpers"},
+		{name: "04 truncate", template: `{{ .Title | truncate 15 }}`, expected: "Czytelny kod…"},
+		{name: "05 upper and lower", template: `{{ upper .Language }} {{ lower "ABC" }}`, expected: "PL abc"},
+		{name: "06 default", template: `{{ .Author | default "Anonymous" }} {{ .Language | default "en" }}`, expected: "Anonymous pl"},
+		{name: "07 join", template: `{{ .Meta.tags | join ", " }}`, expected: "delphi, refactoring"},
+		{name: "08 readingTime", template: `{{ readingTime .Content .Language }} min`, expected: "1 min"},
+		{name: "09 markdownify", template: `{{ .Meta.intro | markdownify }}`, expected: "Use <strong>class helpers</strong> <button>now</button>"},
+		{
+			name:     "10 markdownify is sanitized in safe mode",
+			template: `{{ .Meta.intro | markdownify }}`,
+			options:  ConvertOptions{SafeHTML: true},
+			expected: "Use <strong>class helpers</strong> now",
+		},
+		{name: "11 safeHTML", template: `{{ "<hr>" | safeHTML }} {{ "<hr>" }}`, expected: "<hr> &lt;hr&gt;"},
+		{name: "12 text/template", template: `{{ .Date | formatDate "January 2006" "pl" | upper }}`, options: ConvertOptions{TextTemplate: true}, expected: "MARZEC 2026"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ConvertMarkdownDocument(markdown, tt.template, "", tt.options)
+			td.CmpNoError(t, err)
+			td.Cmp(t, result.HTML, tt.expected)
+		})
+	}
+}
+
+func TestDateIsEmptyWithoutFrontMatterDate(t *testing.T) {
+	template := `{{ if .Date }}[date:{{ .Date }}]{{ end }}{{ .ParsedDate.IsZero }}`
+
+	withoutDate, err := ConvertMarkdownDocument("---\ntitle: Post\n---\nText", template, "", ConvertOptions{})
+	td.CmpNoError(t, err)
+	td.Cmp(t, withoutDate.HTML, "true")
+
+	withDate, err := ConvertMarkdownDocument("---\ndate: 2026-03-13\n---\nText", template, "", ConvertOptions{})
+	td.CmpNoError(t, err)
+	td.Cmp(t, withDate.HTML, "[date:2026-03-13]false")
+}
+
+func TestMarkdownifyReportsDiagnostics(t *testing.T) {
+	markdown := "---\nintro: \"<button>Buy</button> now\"\n---\nText <iframe></iframe>"
+
+	result, err := ConvertMarkdownDocument(markdown, `{{ .Content }}{{ .Meta.intro | markdownify }}`, "", ConvertOptions{SafeHTML: true})
+
+	td.CmpNoError(t, err)
+	td.Cmp(t, result.HTML, "<p>Text </p>\nBuy now")
+	td.Cmp(t, result.Diagnostics, []Diagnostic{
+		{Element: "<iframe>", Reason: "tag is not allowed"},
+		{Element: "<button>", Reason: "tag is not allowed"},
+	})
+}
+
+func TestReadingTime(t *testing.T) {
+	words := ""
+	for i := 0; i < 201; i++ {
+		words += "word "
+	}
+
+	td.Cmp(t, readingTime("", ""), 1)
+	td.Cmp(t, readingTime("<p>"+words+"</p>", ""), 2)
+	td.Cmp(t, readingTime("<p>"+words+"</p>", "en"), 1)
+	td.Cmp(t, readingTime("<p>"+words+"</p>", "pl"), 2)
+	td.Cmp(t, readingTime("<p>"+words+"</p>", " EN-us "), 1)
+	td.Cmp(t, readingTime("<p>word</p><pre><code>"+words+"</code></pre><p><code>"+words+"</code></p>", ""), 1)
+}
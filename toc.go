@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+const tocMarker = "[[toc]]"
+const minHeadingLevel = 1
+const maxHeadingLevel = 6
+
+// parseTocLevels reads the "min-max" (or single "level") range of the tocLevels front matter key.
+// An empty or malformed value selects all heading levels.
+func parseTocLevels(value string) (int, int) {
+	minValue, maxValue, isRange := strings.Cut(value, "-")
+	if !isRange {
+		maxValue = minValue
+	}
+
+	minLevel, minErr := strconv.Atoi(strings.TrimSpace(minValue))
+	maxLevel, maxErr := strconv.Atoi(strings.TrimSpace(maxValue))
+	if minErr != nil || maxErr != nil || minLevel < minHeadingLevel || maxLevel > maxHeadingLevel || minLevel > maxLevel {
+		return minHeadingLevel, maxHeadingLevel
+	}
+
+	return minLevel, maxLevel
+}
+
+func collectDocumentHeadings(doc *Document, minLevel, maxLevel int) []DocumentHeading {
+	var headings []DocumentHeading
+	walkBlocks(doc.Children, func(block Block) {
+		heading, ok := block.(*Heading)
+		if !ok || heading.Level < minLevel || heading.Level > maxLevel {
+			return
+		}
+
+		headings = append(headings, DocumentHeading{
+			Level: heading.Level,
+			Text:  inlinePlainText(heading.Content.Inlines),
+			ID:    heading.ID,
+		})
+	})
+
+	return headings
+}
+
+// renderTableOfContents renders headings as nested lists of links wrapped in <nav class="toc">
+func renderTableOfContents(headings []DocumentHeading) string {
+	if len(headings) == 0 {
+		return ""
+	}
+
+	renderer := &htmlRenderer{}
+	renderer.out.WriteString("<nav class=\"toc\">\n")
+	renderer.renderList(buildTocList(headings), 0)
+	renderer.out.WriteString("</nav>\n")
+	return renderer.out.String()
+}
+
+// buildTocList nests every heading below the closest preceding heading of a lower level
+func buildTocList(headings []DocumentHeading) *List {
+	type openList struct {
+		list  *List
+		level int
+	}
+
+	root := &List{}
+	openLists := []openList{{list: root, level: headings[0].Level}}
+	for _, heading := range headings {
+		for len(openLists) > 1 && heading.Level < openLists[len(openLists)-1].level {
+			openLists = openLists[:len(openLists)-1]
+		}
+
+		current := openLists[len(openLists)-1]
+		if heading.Level > current.level && len(current.list.Items) > 0 {
+			parent := current.list.Items[len(current.list.Items)-1]
+			nested := &List{}
+			parent.Children = append(parent.Children, nested)
+			current = openList{list: nested, level: heading.Level}
+			openLists = append(openLists, current)
+		}
+
+		current.list.Items = append(current.list.Items, &ListItem{Content: InlineContent{
+			Inlines: []Inline{&Link{Destination: "#" + heading.ID, Children: []Inline{&Text{Value: heading.Text}}}},
+		}})
+	}
+
+	return root
+}
@@ -0,0 +1,404 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The TOML front matter parser supports key/value pairs with bare, quoted and dotted keys,
+// [tables], [[arrays of tables]], basic and literal strings (also multi-line), integers,
+// floats, booleans, arrays and inline tables. Dates and times are kept as strings.
+
+var tomlBareKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+`)
+var tomlDateTimePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(?:[Tt ]\d{2}:\d{2}(?::\d{2}(?:\.\d+)?)?(?:[Zz]|[+-]\d{2}:\d{2})?)?$|^\d{2}:\d{2}(?::\d{2}(?:\.\d+)?)?$`)
+var tomlIntegerPattern = regexp.MustCompile(`^[+-]?(?:0|[1-9](?:_?[0-9])*)$`)
+var tomlFloatPattern = regexp.MustCompile(`^[+-]?(?:0|[1-9](?:_?[0-9])*)(?:\.[0-9](?:_?[0-9])*)?(?:[eE][+-]?[0-9](?:_?[0-9])*)?$`)
+
+type tomlParser struct {
+	text      string
+	pos       int
+	firstLine int             // file line number of the first line of text
+	current   map[string]any  // table receiving the key/value pairs
+	defined   map[string]bool // tables declared with a [header]
+}
+
+// parseTOMLFrontMatter parses the lines between the "+++" delimiters into a map.
+// firstLine is the line number of lines[0], used in error messages.
+func parseTOMLFrontMatter(lines []string, firstLine int) (map[string]any, error) {
+	root := map[string]any{}
+	parser := &tomlParser{text: strings.Join(lines, "\n"), firstLine: firstLine, current: root, defined: map[string]bool{}}
+
+	for {
+		parser.skipWhitespaceAndComments(true)
+		if parser.pos >= len(parser.text) {
+			return root, nil
+		}
+
+		var err error
+		if strings.HasPrefix(parser.text[parser.pos:], "[") {
+			err = parser.parseTableHeader(root)
+		} else {
+			err = parser.parseKeyValue(parser.current)
+		}
+		if err == nil {
+			err = parser.expectLineEnd()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *tomlParser) errorf(format string, args ...any) error {
+	return &frontMatterError{Line: p.firstLine + strings.Count(p.text[:p.pos], "\n"), Message: fmt.Sprintf(format, args...)}
+}
+
+// skipWhitespaceAndComments skips spaces, tabs and comments, and line breaks when multiline is set
+func (p *tomlParser) skipWhitespaceAndComments(multiline bool) {
+	for p.pos < len(p.text) {
+		switch char := p.text[p.pos]; {
+		case char == ' ' || char == '\t' || char == '\r':
+			p.pos++
+		case char == '\n' && multiline:
+			p.pos++
+		case char == '#':
+			if end := strings.IndexByte(p.text[p.pos:], '\n'); end >= 0 {
+				p.pos += end
+			} else {
+				p.pos = len(p.text)
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *tomlParser) expectLineEnd() error {
+	p.skipWhitespaceAndComments(false)
+	if p.pos < len(p.text) && p.text[p.pos] != '\n' {
+		return p.errorf("unexpected %q at the end of the line", p.restOfLine())
+	}
+	return nil
+}
+
+func (p *tomlParser) restOfLine() string {
+	line, _, _ := strings.Cut(p.text[p.pos:], "\n")
+	return strings.TrimSpace(line)
+}
+
+// parseTableHeader reads "[a.b]" or "[[items]]" and makes it the current table
+func (p *tomlParser) parseTableHeader(root map[string]any) error {
+	isArray := strings.HasPrefix(p.text[p.pos:], "[[")
+	if isArray {
+		p.pos += 2
+	} else {
+		p.pos++
+	}
+
+	path, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+
+	closing := "]"
+	if isArray {
+		closing = "]]"
+	}
+	if !strings.HasPrefix(p.text[p.pos:], closing) {
+		return p.errorf("table header is not closed with %q", closing)
+	}
+	p.pos += len(closing)
+
+	table := root
+	for _, key := range path[:len(path)-1] {
+		if table, err = p.subtable(table, key); err != nil {
+			return err
+		}
+	}
+
+	last := path[len(path)-1]
+	name := strings.Join(path, ".")
+	if isArray {
+		array, exists := table[last].([]any)
+		if _, taken := table[last]; taken && !exists {
+			return p.errorf("key %q is already defined", name)
+		}
+		p.current = map[string]any{}
+		table[last] = append(array, p.current)
+		return nil
+	}
+
+	if p.defined[name] {
+		return p.errorf("table %q is already defined", name)
+	}
+	p.defined[name] = true
+	p.current, err = p.subtable(table, last)
+	return err
+}
+
+// subtable returns the table stored under key, creating it when missing. For an array
+// of tables it is the last table of the array.
+func (p *tomlParser) subtable(table map[string]any, key string) (map[string]any, error) {
+	switch value := table[key].(type) {
+	case nil:
+		subtable := map[string]any{}
+		table[key] = subtable
+		return subtable, nil
+	case map[string]any:
+		return value, nil
+	case []any:
+		if len(value) > 0 {
+			if last, ok := value[len(value)-1].(map[string]any); ok {
+				return last, nil
+			}
+		}
+	}
+
+	return nil, p.errorf("key %q is already defined", key)
+}
+
+// parseKeyValue reads "key = value" into table; dotted keys create nested tables
+func (p *tomlParser) parseKeyValue(table map[string]any) error {
+	path, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+
+	p.skipWhitespaceAndComments(false)
+	if p.pos >= len(p.text) || p.text[p.pos] != '=' {
+		return p.errorf("expected \"key = value\", got %q", p.restOfLine())
+	}
+	p.pos++
+	p.skipWhitespaceAndComments(false)
+
+	for _, key := range path[:len(path)-1] {
+		if table, err = p.subtable(table, key); err != nil {
+			return err
+		}
+	}
+
+	last := path[len(path)-1]
+	if _, exists := table[last]; exists {
+		return p.errorf("duplicate key %q", strings.Join(path, "."))
+	}
+
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+	table[last] = value
+	return nil
+}
+
+// parseKey reads a bare, quoted or dotted key and returns its parts
+func (p *tomlParser) parseKey() ([]string, error) {
+	var path []string
+	for {
+		p.skipWhitespaceAndComments(false)
+		rest := p.text[p.pos:]
+
+		switch {
+		case strings.HasPrefix(rest, "\"") || strings.HasPrefix(rest, "'"):
+			key, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+			path = append(path, key)
+		case tomlBareKeyPattern.MatchString(rest):
+			key := tomlBareKeyPattern.FindString(rest)
+			path = append(path, key)
+			p.pos += len(key)
+		default:
+			return nil, p.errorf("expected a key, got %q", p.restOfLine())
+		}
+
+		p.skipWhitespaceAndComments(false)
+		if p.pos >= len(p.text) || p.text[p.pos] != '.' {
+			return path, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *tomlParser) parseValue() (any, error) {
+	if p.pos >= len(p.text) || p.text[p.pos] == '\n' {
+		return nil, p.errorf("missing value")
+	}
+
+	switch p.text[p.pos] {
+	case '"', '\'':
+		return p.parseString()
+	case '[':
+		return p.parseArray()
+	case '{':
+		return p.parseInlineTable()
+	}
+
+	end := p.pos
+	for end < len(p.text) && !strings.ContainsRune(",]}#\n", rune(p.text[end])) {
+		end++
+	}
+	token := strings.TrimRight(p.text[p.pos:end], " \t\r")
+
+	value, ok := resolveTOMLScalar(token)
+	if !ok {
+		return nil, p.errorf("invalid value %q", token)
+	}
+	p.pos += len(token)
+	return value, nil
+}
+
+// resolveTOMLScalar reads a boolean, number, date or time
+func resolveTOMLScalar(token string) (any, bool) {
+	switch {
+	case token == "true":
+		return true, true
+	case token == "false":
+		return false, true
+	case tomlDateTimePattern.MatchString(token):
+		return token, true
+	case tomlIntegerPattern.MatchString(token):
+		number, err := strconv.Atoi(strings.ReplaceAll(token, "_", ""))
+		return number, err == nil
+	case len(token) > 2 && token[0] == '0' && strings.ContainsRune("xob", rune(token[1])):
+		base := map[byte]int{'x': 16, 'o': 8, 'b': 2}[token[1]]
+		number, err := strconv.ParseInt(strings.ReplaceAll(token[2:], "_", ""), base, 64)
+		return int(number), err == nil
+	case tomlFloatPattern.MatchString(token):
+		number, err := strconv.ParseFloat(strings.ReplaceAll(token, "_", ""), 64)
+		return number, err == nil
+	}
+
+	return nil, false
+}
+
+// parseString reads a basic ("..."), literal ('...') or multi-line ("""...""" or ”'...”') string
+func (p *tomlParser) parseString() (string, error) {
+	rest := p.text[p.pos:]
+	quote := rest[:1]
+	if strings.HasPrefix(rest, strings.Repeat(quote, 3)) {
+		return p.parseMultilineString(strings.Repeat(quote, 3))
+	}
+
+	var value strings.Builder
+	for pos := 1; pos < len(rest) && rest[pos] != '\n'; pos++ {
+		char := rest[pos]
+		switch {
+		case char == quote[0]:
+			p.pos += pos + 1
+			return value.String(), nil
+		case char == '\\' && quote == "\"" && pos+1 < len(rest):
+			escaped, length, err := parseYAMLEscape(rest[pos:])
+			if err != nil {
+				p.pos += pos
+				return "", p.errorf("%s", err)
+			}
+			value.WriteString(escaped)
+			pos += length - 1
+		default:
+			value.WriteByte(char)
+		}
+	}
+
+	return "", p.errorf("unterminated string")
+}
+
+func (p *tomlParser) parseMultilineString(delimiter string) (string, error) {
+	start := p.pos + len(delimiter)
+	end := strings.Index(p.text[start:], delimiter)
+	if end < 0 {
+		return "", p.errorf("unterminated multi-line string")
+	}
+	// up to two quotes may directly precede the closing delimiter
+	for extra := 0; extra < 2 && start+end+len(delimiter) < len(p.text) && p.text[start+end+len(delimiter)] == delimiter[0]; extra++ {
+		end++
+	}
+
+	raw := strings.TrimPrefix(p.text[start:start+end], "\n")
+	p.pos = start + end + len(delimiter)
+	if delimiter == "'''" {
+		return raw, nil
+	}
+
+	var value strings.Builder
+	for pos := 0; pos < len(raw); pos++ {
+		if raw[pos] != '\\' || pos+1 >= len(raw) {
+			value.WriteByte(raw[pos])
+			continue
+		}
+
+		// a backslash at the end of a line removes the line break and the following whitespace
+		if trimmed := strings.TrimLeft(raw[pos+1:], " \t\r"); strings.HasPrefix(trimmed, "\n") {
+			pos = len(raw) - len(strings.TrimLeft(trimmed, " \t\r\n")) - 1
+			continue
+		}
+
+		escaped, length, err := parseYAMLEscape(raw[pos:])
+		if err != nil {
+			return "", p.errorf("%s", err)
+		}
+		value.WriteString(escaped)
+		pos += length - 1
+	}
+
+	return value.String(), nil
+}
+
+// parseArray reads "[1, 2, 3]"; the array may span lines and contain comments
+func (p *tomlParser) parseArray() ([]any, error) {
+	start := p.pos
+	p.pos++
+
+	array := []any{}
+	for {
+		p.skipWhitespaceAndComments(true)
+		if p.pos >= len(p.text) {
+			p.pos = start
+			return nil, p.errorf("array is not closed with \"]\"")
+		}
+		if p.text[p.pos] == ']' {
+			p.pos++
+			return array, nil
+		}
+
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, value)
+
+		p.skipWhitespaceAndComments(true)
+		if p.pos < len(p.text) && p.text[p.pos] == ',' {
+			p.pos++
+		} else if p.pos < len(p.text) && p.text[p.pos] != ']' {
+			return nil, p.errorf("expected \",\" or \"]\" in an array, got %q", p.restOfLine())
+		}
+	}
+}
+
+// parseInlineTable reads "{ a = 1, b.c = 2 }" written on one line
+func (p *tomlParser) parseInlineTable() (map[string]any, error) {
+	p.pos++
+
+	table := map[string]any{}
+	for {
+		p.skipWhitespaceAndComments(false)
+		if p.pos < len(p.text) && p.text[p.pos] == '}' {
+			p.pos++
+			return table, nil
+		}
+
+		if err := p.parseKeyValue(table); err != nil {
+			return nil, err
+		}
+
+		p.skipWhitespaceAndComments(false)
+		if p.pos < len(p.text) && p.text[p.pos] == ',' {
+			p.pos++
+		} else if p.pos >= len(p.text) || p.text[p.pos] != '}' {
+			return nil, p.errorf("inline table is not closed with \"}\"")
+		}
+	}
+}
@@ -0,0 +1,480 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The front matter parser understands the subset of YAML used for document metadata:
+// block mappings and sequences nested by indentation, flow collections written on one line
+// ([a, b] and {a: 1}), plain, single- and double-quoted scalars, "|" and ">" block scalars
+// and comments. Anchors, aliases, tags and multi-document streams are not supported.
+
+var yamlIntegerPattern = regexp.MustCompile(`^[-+]?[0-9]+$`)
+var yamlFloatPattern = regexp.MustCompile(`^[-+]?(?:\.[0-9]+|[0-9]+(?:\.[0-9]*)?)(?:[eE][-+]?[0-9]+)?$`)
+var yamlBlockScalarPattern = regexp.MustCompile(`^([|>])([-+]?)([1-9]?)[ \t]*(?:#.*)?$`)
+
+type yamlParser struct {
+	lines     []string
+	firstLine int // file line number of lines[0]
+	pos       int
+}
+
+// parseYAMLFrontMatter parses the lines between the "---" delimiters into a map.
+// firstLine is the line number of lines[0], used in error messages.
+func parseYAMLFrontMatter(lines []string, firstLine int) (map[string]any, error) {
+	// lines are copied, "- key: value" items are rewritten while parsing
+	parser := &yamlParser{lines: append([]string(nil), lines...), firstLine: firstLine}
+	if !parser.skipToContent() {
+		return map[string]any{}, nil
+	}
+
+	indent, text := parser.current()
+	if indent > 0 {
+		return nil, parser.errorf("unexpected indentation")
+	}
+	if isYAMLSequenceItem(text) {
+		return nil, parser.errorf("front matter must be a mapping of keys to values")
+	}
+
+	meta, err := parser.parseMapping(0)
+	if err != nil {
+		return nil, err
+	}
+	if parser.skipToContent() {
+		return nil, parser.errorf("unexpected indentation")
+	}
+
+	return meta, nil
+}
+
+func (p *yamlParser) errorf(format string, args ...any) error {
+	return &frontMatterError{Line: p.firstLine + p.pos, Message: fmt.Sprintf(format, args...)}
+}
+
+// skipToContent moves to the next line that is neither blank nor a comment
+func (p *yamlParser) skipToContent() bool {
+	for ; p.pos < len(p.lines); p.pos++ {
+		trimmed := strings.TrimSpace(p.lines[p.pos])
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			return true
+		}
+	}
+	return false
+}
+
+// current returns the indentation and the text of the current line
+func (p *yamlParser) current() (int, string) {
+	line := strings.TrimRight(p.lines[p.pos], " \t\r")
+	text := strings.TrimLeft(line, " ")
+	return len(line) - len(text), text
+}
+
+func isYAMLSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// parseNode reads the mapping or sequence nested deeper than parentIndent, nil when there is none
+func (p *yamlParser) parseNode(parentIndent int) (any, error) {
+	if !p.skipToContent() {
+		return nil, nil
+	}
+
+	indent, text := p.current()
+	if indent <= parentIndent {
+		return nil, nil
+	}
+	if isYAMLSequenceItem(text) {
+		return p.parseSequence(indent)
+	}
+	return p.parseMapping(indent)
+}
+
+func (p *yamlParser) parseMapping(indent int) (map[string]any, error) {
+	mapping := map[string]any{}
+	for p.skipToContent() {
+		lineIndent, text := p.current()
+		if lineIndent < indent {
+			break
+		}
+		if lineIndent > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+		if strings.HasPrefix(text, "\t") {
+			return nil, p.errorf("tabs are not allowed for indentation")
+		}
+		if isYAMLSequenceItem(text) {
+			return nil, p.errorf("sequence item where a key was expected")
+		}
+
+		key, rest, ok := splitYAMLKey(text)
+		if !ok {
+			return nil, p.errorf("expected \"key: value\", got %q", text)
+		}
+		if _, exists := mapping[key]; exists {
+			return nil, p.errorf("duplicate key %q", key)
+		}
+
+		value, err := p.parseValue(indent, rest, true)
+		if err != nil {
+			return nil, err
+		}
+		mapping[key] = value
+	}
+
+	return mapping, nil
+}
+
+func (p *yamlParser) parseSequence(indent int) ([]any, error) {
+	sequence := []any{}
+	for p.skipToContent() {
+		lineIndent, text := p.current()
+		if lineIndent < indent || (lineIndent == indent && !isYAMLSequenceItem(text)) {
+			break
+		}
+		if lineIndent > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+
+		item := strings.TrimLeft(strings.TrimPrefix(text, "-"), " ")
+		if _, _, isMapping := splitYAMLKey(item); isMapping {
+			// "- key: value" starts a mapping indented to the position of the key
+			itemIndent := indent + len(text) - len(item)
+			p.lines[p.pos] = strings.Repeat(" ", itemIndent) + item
+			mapping, err := p.parseMapping(itemIndent)
+			if err != nil {
+				return nil, err
+			}
+			sequence = append(sequence, mapping)
+			continue
+		}
+
+		value, err := p.parseValue(indent, item, false)
+		if err != nil {
+			return nil, err
+		}
+		sequence = append(sequence, value)
+	}
+
+	return sequence, nil
+}
+
+// parseValue reads the value written after "key:" or "-" on the current line,
+// continuing with the nested lines when the value is a block. A sequence that is
+// the value of a key may be written at the indentation of the key.
+func (p *yamlParser) parseValue(indent int, text string, isKeyValue bool) (any, error) {
+	text = strings.TrimSpace(text)
+	if text == "" || strings.HasPrefix(text, "#") {
+		p.pos++
+		if isKeyValue && p.skipToContent() {
+			if lineIndent, next := p.current(); lineIndent == indent && isYAMLSequenceItem(next) {
+				return p.parseSequence(indent)
+			}
+		}
+		return p.parseNode(indent)
+	}
+
+	if match := yamlBlockScalarPattern.FindStringSubmatch(text); match != nil {
+		p.pos++
+		return p.parseBlockScalar(indent, match[1] == ">", match[2], match[3]), nil
+	}
+
+	value, err := parseYAMLInline(text)
+	if err != nil {
+		return nil, p.errorf("%s", err)
+	}
+	p.pos++
+	return value, nil
+}
+
+// parseBlockScalar reads the lines of a "|" (literal) or ">" (folded) scalar.
+// chomping is "-" (strip), "+" (keep) or "" (single final line break).
+func (p *yamlParser) parseBlockScalar(parentIndent int, folded bool, chomping string, indentation string) string {
+	contentIndent := 0
+	if indentation != "" {
+		contentIndent = parentIndent + int(indentation[0]-'0')
+	}
+
+	var lines []string
+	for ; p.pos < len(p.lines); p.pos++ {
+		line := strings.TrimRight(p.lines[p.pos], " \t\r")
+		if line == "" {
+			lines = append(lines, "")
+			continue
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if contentIndent == 0 {
+			contentIndent = indent
+		}
+		if indent < contentIndent || indent <= parentIndent {
+			break
+		}
+		lines = append(lines, line[contentIndent:])
+	}
+
+	trailingBreaks := 0
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+		trailingBreaks++
+	}
+
+	var text string
+	if folded {
+		text = foldYAMLLines(lines)
+	} else {
+		text = strings.Join(lines, "\n")
+	}
+
+	switch {
+	case len(lines) == 0 || chomping == "-":
+		return text
+	case chomping == "+":
+		return text + strings.Repeat("\n", trailingBreaks+1)
+	default:
+		return text + "\n"
+	}
+}
+
+// foldYAMLLines joins lines with spaces; empty lines and more indented lines keep their line breaks
+func foldYAMLLines(lines []string) string {
+	var text strings.Builder
+	for idx, line := range lines {
+		if idx > 0 {
+			previous := lines[idx-1]
+			switch {
+			case line == "":
+				text.WriteString("\n")
+			case previous == "":
+				// the line break is already written by the empty line
+			case strings.HasPrefix(line, " ") || strings.HasPrefix(previous, " "):
+				text.WriteString("\n")
+			default:
+				text.WriteString(" ")
+			}
+		}
+		text.WriteString(line)
+	}
+	return text.String()
+}
+
+// splitYAMLKey splits "key: value" into the key and the rest of the line
+func splitYAMLKey(text string) (string, string, bool) {
+	if text == "" || strings.ContainsRune("[{#&*!|>%@`", rune(text[0])) {
+		return "", "", false
+	}
+
+	if text[0] == '"' || text[0] == '\'' {
+		key, length, err := parseYAMLQuoted(text)
+		if err != nil {
+			return "", "", false
+		}
+		rest := text[length:]
+		if !strings.HasPrefix(rest, ":") || (len(rest) > 1 && rest[1] != ' ' && rest[1] != '\t') {
+			return "", "", false
+		}
+		return key, rest[1:], true
+	}
+
+	for idx := 0; idx < len(text); idx++ {
+		if text[idx] == ':' && (idx+1 == len(text) || text[idx+1] == ' ' || text[idx+1] == '\t') {
+			key := strings.TrimSpace(text[:idx])
+			return key, text[idx+1:], key != ""
+		}
+		if text[idx] == '#' && idx > 0 && (text[idx-1] == ' ' || text[idx-1] == '\t') {
+			break
+		}
+	}
+
+	return "", "", false
+}
+
+// parseYAMLInline reads a scalar or a flow collection that takes the rest of the line
+func parseYAMLInline(text string) (any, error) {
+	var value any
+	var length int
+	var err error
+
+	switch text[0] {
+	case '"', '\'':
+		value, length, err = parseYAMLQuoted(text)
+	case '[', '{':
+		value, length, err = parseYAMLFlow(text)
+	default:
+		return resolveYAMLPlainScalar(stripYAMLComment(text)), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if rest := strings.TrimSpace(text[length:]); rest != "" && !strings.HasPrefix(rest, "#") {
+		return nil, fmt.Errorf("unexpected %q after the value", rest)
+	}
+	return value, nil
+}
+
+// stripYAMLComment removes a " # comment" from a plain scalar
+func stripYAMLComment(text string) string {
+	for idx := 1; idx < len(text); idx++ {
+		if text[idx] == '#' && (text[idx-1] == ' ' || text[idx-1] == '\t') {
+			return strings.TrimSpace(text[:idx])
+		}
+	}
+	return strings.TrimSpace(text)
+}
+
+// resolveYAMLPlainScalar gives an unquoted scalar its type: null, bool, int, float or string
+func resolveYAMLPlainScalar(text string) any {
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+
+	if yamlIntegerPattern.MatchString(text) {
+		if number, err := strconv.Atoi(text); err == nil {
+			return number
+		}
+	}
+	if yamlFloatPattern.MatchString(text) {
+		if number, err := strconv.ParseFloat(text, 64); err == nil {
+			return number
+		}
+	}
+
+	return text
+}
+
+// parseYAMLQuoted reads a single- or double-quoted scalar at the start of text
+func parseYAMLQuoted(text string) (string, int, error) {
+	quote := text[0]
+	var value strings.Builder
+	for pos := 1; pos < len(text); pos++ {
+		char := text[pos]
+		switch {
+		case char == quote && quote == '\'' && pos+1 < len(text) && text[pos+1] == '\'':
+			value.WriteByte('\'')
+			pos++
+		case char == quote:
+			return value.String(), pos + 1, nil
+		case char == '\\' && quote == '"' && pos+1 < len(text):
+			escaped, length, err := parseYAMLEscape(text[pos:])
+			if err != nil {
+				return "", 0, err
+			}
+			value.WriteString(escaped)
+			pos += length - 1
+		default:
+			value.WriteByte(char)
+		}
+	}
+
+	return "", 0, fmt.Errorf("unterminated quoted string")
+}
+
+var yamlEscapes = map[byte]string{
+	'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", 'n': "\n", 'v': "\v", 'f': "\f", 'r': "\r",
+	'e': "\x1b", ' ': " ", '"': "\"", '/': "/", '\\': "\\", 'N': "\u0085", '_': " ",
+}
+
+// parseYAMLEscape reads a "\n" or "é" style escape sequence of a double-quoted scalar
+func parseYAMLEscape(text string) (string, int, error) {
+	if escaped, ok := yamlEscapes[text[1]]; ok {
+		return escaped, 2, nil
+	}
+
+	digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[text[1]]
+	if digits == 0 || len(text) < 2+digits {
+		return "", 0, fmt.Errorf("invalid escape sequence %q", text[:2])
+	}
+
+	code, err := strconv.ParseUint(text[2:2+digits], 16, 32)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid escape sequence %q", text[:2+digits])
+	}
+	return string(rune(code)), 2 + digits, nil
+}
+
+// parseYAMLFlow reads a "[a, b]" sequence or a "{a: 1}" mapping at the start of text
+func parseYAMLFlow(text string) (any, int, error) {
+	closing := byte(']')
+	if text[0] == '{' {
+		closing = '}'
+	}
+
+	var sequence []any
+	mapping := map[string]any{}
+	pos := 1
+	for {
+		pos += len(text[pos:]) - len(strings.TrimLeft(text[pos:], " \t"))
+		if pos >= len(text) {
+			return nil, 0, fmt.Errorf("unterminated flow collection, %q expected", closing)
+		}
+		if text[pos] == closing {
+			break
+		}
+
+		var key string
+		if closing == '}' {
+			end := strings.IndexByte(text[pos:], ':')
+			if end < 0 {
+				return nil, 0, fmt.Errorf("expected \"key: value\" in a flow mapping")
+			}
+			key = strings.Trim(strings.TrimSpace(text[pos:pos+end]), `"'`)
+			pos += end + 1
+			pos += len(text[pos:]) - len(strings.TrimLeft(text[pos:], " \t"))
+		}
+
+		value, length, err := parseYAMLFlowItem(text[pos:])
+		if err != nil {
+			return nil, 0, err
+		}
+		pos += length
+
+		if closing == '}' {
+			mapping[key] = value
+		} else {
+			sequence = append(sequence, value)
+		}
+
+		pos += len(text[pos:]) - len(strings.TrimLeft(text[pos:], " \t"))
+		if pos < len(text) && text[pos] == ',' {
+			pos++
+		} else if pos >= len(text) || text[pos] != closing {
+			return nil, 0, fmt.Errorf("unterminated flow collection, %q expected", closing)
+		}
+	}
+
+	if closing == '}' {
+		return mapping, pos + 1, nil
+	}
+	if sequence == nil {
+		sequence = []any{}
+	}
+	return sequence, pos + 1, nil
+}
+
+// parseYAMLFlowItem reads one element of a flow collection
+func parseYAMLFlowItem(text string) (any, int, error) {
+	if text == "" {
+		return nil, 0, fmt.Errorf("unterminated flow collection")
+	}
+
+	switch text[0] {
+	case '"', '\'':
+		return parseYAMLQuoted(text)
+	case '[', '{':
+		return parseYAMLFlow(text)
+	}
+
+	end := strings.IndexAny(text, ",]}")
+	if end < 0 {
+		end = len(text)
+	}
+	return resolveYAMLPlainScalar(strings.TrimSpace(text[:end])), end, nil
+}